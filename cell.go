@@ -17,8 +17,21 @@ const (
 	SYMMETRY_HORIZONTAL
 	SYMMETRY_VERTICAL
 	SYMMETRY_BOTH
+	//SYMMETRY_DIAGONAL mirrors across the diagonal from the top left to the
+	//bottom right.
+	SYMMETRY_DIAGONAL
+	//SYMMETRY_ANTI_DIAGONAL mirrors across the diagonal from the top right to
+	//the bottom left.
+	SYMMETRY_ANTI_DIAGONAL
+	//SYMMETRY_ROTATIONAL_90 looks the same when the grid is rotated a
+	//quarter turn. Cells come in groups of four instead of pairs.
+	SYMMETRY_ROTATIONAL_90
 )
 
+//SYMMETRY_ROTATIONAL_180 looks the same when the grid is rotated half a turn,
+//the look of most published puzzles. It is the same as SYMMETRY_BOTH.
+const SYMMETRY_ROTATIONAL_180 = SYMMETRY_BOTH
+
 //Cell represents a single cell within a grid. It maintains information about
 //the number that is filled, the numbers that are currently legal given the
 //filled status of its neighbors, and whether any possibilities have been
@@ -78,7 +91,9 @@ type Cell interface {
 	Locked() bool
 
 	//SymmetricalPartner returns the cell's partner in the grid, based on the type
-	//of symmetry requested.
+	//of symmetry requested. For SYMMETRY_ROTATIONAL_90, which has groups of
+	//four cells, it returns the next cell clockwise; calling it repeatedly
+	//visits the whole group.
 	SymmetricalPartner(symmetry SymmetryType) Cell
 
 	//Neighbors returns a CellSlice of all of the cell's neighbors--the other
@@ -615,14 +630,56 @@ func (self *mutableCellImpl) MutableSymmetricalPartner(symmetry SymmetryType) Mu
 	return result.MutableInGrid(self.mutableGridRef)
 }
 
-func (self *cellImpl) SymmetricalPartner(symmetry SymmetryType) Cell {
+//anySymmetryTypes are the types of symmetry SYMMETRY_ANY chooses from.
+var anySymmetryTypes = []SymmetryType{SYMMETRY_BOTH, SYMMETRY_HORIZONTAL, SYMMETRY_HORIZONTAL, SYMMETRY_VERTICAL, SYMMETRY_DIAGONAL, SYMMETRY_ANTI_DIAGONAL, SYMMETRY_ROTATIONAL_90}
 
+//concreteSymmetry returns symmetry, unless it is SYMMETRY_ANY, in which case
+//it returns a specific type of symmetry at random.
+func concreteSymmetry(symmetry SymmetryType) SymmetryType {
 	if symmetry == SYMMETRY_ANY {
-		//TODO: don't chose a type of smmetry that doesn't have a partner
-		typesOfSymmetry := []SymmetryType{SYMMETRY_BOTH, SYMMETRY_HORIZONTAL, SYMMETRY_HORIZONTAL, SYMMETRY_VERTICAL}
-		symmetry = typesOfSymmetry[rand.Intn(len(typesOfSymmetry))]
+		return anySymmetryTypes[rand.Intn(len(anySymmetryTypes))]
+	}
+	return symmetry
+}
+
+//concreteSymmetryForCell is like concreteSymmetry, but if symmetry is
+//SYMMETRY_ANY it only chooses a type that gives cell a partner, if there is
+//one. Cells on a diagonal, for example, have no partner across it.
+func concreteSymmetryForCell(cell Cell, symmetry SymmetryType) SymmetryType {
+	if symmetry != SYMMETRY_ANY {
+		return symmetry
+	}
+	for _, i := range rand.Perm(len(anySymmetryTypes)) {
+		if cell.SymmetricalPartner(anySymmetryTypes[i]) != nil {
+			return anySymmetryTypes[i]
+		}
+	}
+	return SYMMETRY_NONE
+}
+
+//mutableSymmetricalOrbit returns every other cell that cell is mapped to by
+//repeatedly applying the symmetry: one cell for mirror symmetries, and three
+//for SYMMETRY_ROTATIONAL_90. If symmetry is SYMMETRY_ANY, a single type is
+//chosen at random for the whole orbit.
+func mutableSymmetricalOrbit(cell MutableCell, symmetry SymmetryType) MutableCellSlice {
+	symmetry = concreteSymmetryForCell(cell, symmetry)
+
+	var result MutableCellSlice
+
+	for other := cell.MutableSymmetricalPartner(symmetry); other != nil; other = other.MutableSymmetricalPartner(symmetry) {
+		if other.Row() == cell.Row() && other.Col() == cell.Col() {
+			break
+		}
+		result = append(result, other)
 	}
 
+	return result
+}
+
+func (self *cellImpl) SymmetricalPartner(symmetry SymmetryType) Cell {
+
+	symmetry = concreteSymmetryForCell(self, symmetry)
+
 	var cell Cell
 
 	switch symmetry {
@@ -642,6 +699,24 @@ func (self *cellImpl) SymmetricalPartner(symmetry SymmetryType) Cell {
 		if cell != nil && (cell.Row() != self.Row() || cell.Col() != self.Col()) {
 			return cell
 		}
+
+	case SYMMETRY_DIAGONAL:
+		cell = self.gridRef.Cell(self.Col(), self.Row())
+		if cell != nil && (cell.Row() != self.Row() || cell.Col() != self.Col()) {
+			return cell
+		}
+
+	case SYMMETRY_ANTI_DIAGONAL:
+		cell = self.gridRef.Cell(DIM-self.Col()-1, DIM-self.Row()-1)
+		if cell != nil && (cell.Row() != self.Row() || cell.Col() != self.Col()) {
+			return cell
+		}
+
+	case SYMMETRY_ROTATIONAL_90:
+		cell = self.gridRef.Cell(self.Col(), DIM-self.Row()-1)
+		if cell != nil && (cell.Row() != self.Row() || cell.Col() != self.Col()) {
+			return cell
+		}
 	}
 
 	//If the cell was the same as self, or SYMMETRY_NONE
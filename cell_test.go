@@ -390,4 +390,53 @@ func TestSymmetry(t *testing.T) {
 		t.Error("Middle cell got a mutable symmetrical partner for some kind of symmetry.")
 	}
 
+	if cell.SymmetricalPartner(SYMMETRY_DIAGONAL) != nil || cell.SymmetricalPartner(SYMMETRY_ANTI_DIAGONAL) != nil || cell.SymmetricalPartner(SYMMETRY_ROTATIONAL_90) != nil {
+		t.Error("Middle cell got a symmetrical partner for some kind of new symmetry.")
+	}
+
+	if SYMMETRY_ROTATIONAL_180 != SYMMETRY_BOTH {
+		t.Error("180 degree rotational symmetry wasn't the same as both")
+	}
+
+	tests := []struct {
+		symmetry SymmetryType
+		row, col int
+		orbit    CellRefSlice
+		name     string
+		//A cell that is its own partner
+		onAxisRow, onAxisCol int
+	}{
+		{SYMMETRY_DIAGONAL, 1, 3, CellRefSlice{{3, 1}}, "diagonal", 2, 2},
+		{SYMMETRY_ANTI_DIAGONAL, 1, 3, CellRefSlice{{5, 7}}, "anti-diagonal", 2, 6},
+		{SYMMETRY_ROTATIONAL_90, 1, 3, CellRefSlice{{3, 7}, {7, 5}, {5, 1}}, "rotational 90", 4, 4},
+		{SYMMETRY_BOTH, 1, 3, CellRefSlice{{7, 5}}, "both", 4, 4},
+	}
+
+	for _, test := range tests {
+		cell := grid.MutableCell(test.row, test.col)
+
+		partner := cell.SymmetricalPartner(test.symmetry)
+
+		if partner == nil || partner.Reference() != test.orbit[0] {
+			t.Error("Got wrong symmetrical partner for", test.name, "Got", partner, "expected", test.orbit[0])
+		}
+
+		var orbit CellRefSlice
+		for _, other := range mutableSymmetricalOrbit(cell, test.symmetry) {
+			orbit = append(orbit, other.Reference())
+		}
+
+		if !reflect.DeepEqual(orbit, test.orbit) {
+			t.Error("Got wrong orbit for", test.name, "Got", orbit, "expected", test.orbit)
+		}
+
+		if grid.Cell(test.onAxisRow, test.onAxisCol).SymmetricalPartner(test.symmetry) != nil {
+			t.Error("Cell on the axis of symmetry for", test.name, "had a partner")
+		}
+	}
+
+	if len(mutableSymmetricalOrbit(grid.MutableCell(4, 4), SYMMETRY_ROTATIONAL_90)) != 0 {
+		t.Error("Middle cell had an orbit for rotational 90")
+	}
+
 }
@@ -658,9 +658,8 @@ func histogramPuzzles(a *appOptions, count int, model map[string]float64) {
 		return
 	}
 
-	//TODO: ideally we'd save the current model, do our thing, and then
-	//restore it later. but we can't because we can't get at the current
-	//model. Maybe a Save/RestoreModel in the main library?
+	previousModel := sudoku.CurrentDifficultyModel()
+	defer sudoku.LoadDifficultyModel(previousModel.Weights)
 
 	sudoku.LoadDifficultyModel(model)
 
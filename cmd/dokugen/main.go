@@ -6,7 +6,6 @@ Run with -h to see help on how to use it.
 package main
 
 import (
-	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"flag"
@@ -15,6 +14,7 @@ import (
 	"github.com/gosuri/uiprogress"
 	"github.com/jkomoros/sudoku"
 	"github.com/jkomoros/sudoku/sdkconverter"
+	"github.com/jkomoros/sudoku/sudokurender"
 	"io"
 	"log"
 	"math/rand"
@@ -45,6 +45,9 @@ type appOptions struct {
 	PUZZLE_TO_SOLVE     string
 	NUM                 int
 	PRINT_STATS         bool
+	HODOKU              bool
+	REDUNDANT           bool
+	MINIMAL             bool
 	WALKTHROUGH         bool
 	RAW_SYMMETRY        string
 	RAW_DIFFICULTY      string
@@ -54,10 +57,14 @@ type appOptions struct {
 	MIN_DIFFICULTY      float64
 	MAX_DIFFICULTY      float64
 	NO_CACHE            bool
+	CONCURRENCY         int
 	PUZZLE_FORMAT       string
 	NO_PROGRESS         bool
 	CSV                 bool
 	CONVERTER           sdkconverter.SudokuPuzzleConverter
+	PDF                 string
+	PER_PAGE            int
+	TITLE               string
 	//Only used in testing.
 	FAKE_GENERATE bool
 	flagSet       *flag.FlagSet
@@ -126,19 +133,26 @@ func defineFlags(options *appOptions) {
 	options.flagSet.BoolVar(&options.HELP, "h", false, "If provided, will print help and exit.")
 	options.flagSet.IntVar(&options.NUM, "n", 1, "Number of things to generate")
 	options.flagSet.BoolVar(&options.PRINT_STATS, "p", false, "If provided, will print stats.")
-	options.flagSet.StringVar(&options.PUZZLE_TO_SOLVE, "s", "", "If provided, will solve the puzzle at the given filename and print solution. If -csv is provided, will expect the file to be a csv where the first column of each row is a puzzle in the specified puzzle format.")
+	options.flagSet.StringVar(&options.PUZZLE_TO_SOLVE, "s", "", "If provided, will solve the puzzles at the given filename and print solutions. The file may contain many puzzles, one per line for single-line formats or separated by blank lines. If -csv is provided, will expect the file to be a csv where the first column of each row is a puzzle in the specified puzzle format.")
+	options.flagSet.BoolVar(&options.HODOKU, "hodoku", false, "If provided, will print the HoDoKu level and score of each puzzle.")
+	options.flagSet.BoolVar(&options.REDUNDANT, "redundant", false, "If provided, will print the clues in each puzzle that could be removed without the puzzle gaining more solutions.")
 	options.flagSet.BoolVar(&options.WALKTHROUGH, "w", false, "If provided, will print out a walkthrough to solve the provided puzzle.")
-	options.flagSet.StringVar(&options.RAW_SYMMETRY, "y", "vertical", "Valid values: 'none', 'both', 'horizontal', 'vertical")
+	options.flagSet.StringVar(&options.RAW_SYMMETRY, "y", "vertical", "Valid values: 'none', 'both', 'horizontal', 'vertical', 'rotational' (same as 'rotational-180' and 'both'), 'rotational-90', 'diagonal', 'anti-diagonal'")
 	options.flagSet.Float64Var(&options.SYMMETRY_PROPORTION, "r", 0.7, "What proportion of cells should be filled according to symmetry")
 	options.flagSet.IntVar(&options.MIN_FILLED_CELLS, "min-filled-cells", 0, "The minimum number of cells that should be filled in the generated puzzles.")
+	options.flagSet.BoolVar(&options.MINIMAL, "minimal", false, "If provided, generated puzzles will have no clues that could be removed without the puzzle gaining more solutions.")
 	options.flagSet.Float64Var(&options.MIN_DIFFICULTY, "min", 0.0, "Minimum difficulty for generated puzzle")
 	options.flagSet.Float64Var(&options.MAX_DIFFICULTY, "max", 1.0, "Maximum difficulty for generated puzzle")
 	options.flagSet.BoolVar(&options.NO_CACHE, "no-cache", false, "If provided, will not vend generated puzzles from the cache of previously generated puzzles.")
+	options.flagSet.IntVar(&options.CONCURRENCY, "j", 1, "How many puzzles to generate and rate at once.")
 	//TODO: the format should also be how we interpret loads, too.
-	options.flagSet.StringVar(&options.PUZZLE_FORMAT, "format", "sdk", "Which format to export puzzles from. Defaults to 'sdk'")
+	options.flagSet.StringVar(&options.PUZZLE_FORMAT, "format", "sdk", "Which format to import and export puzzles in: one of sdk, doku, komo, sadman, line, ss, or pm. Defaults to 'sdk'")
 	options.flagSet.BoolVar(&options.CSV, "csv", false, "Export CSV, and expect inbound puzzle files to be a CSV with a puzzle per row.")
 	options.flagSet.StringVar(&options.RAW_DIFFICULTY, "d", "", "difficulty, one of {gentle, easy, medium, tough}")
 	options.flagSet.BoolVar(&options.NO_PROGRESS, "no-progress", false, "If provided, will not print a progress bar")
+	options.flagSet.StringVar(&options.PDF, "pdf", "", "If provided, will also lay out the generated or loaded puzzles in a printable PDF booklet at the given filename, with their difficulties and an answer key.")
+	options.flagSet.IntVar(&options.PER_PAGE, "per-page", sudokurender.DEFAULT_PUZZLES_PER_PAGE, "How many puzzles to put on each page of the -pdf booklet.")
+	options.flagSet.StringVar(&options.TITLE, "title", "", "A title to print at the top of each page of the -pdf booklet.")
 }
 
 //If it returns true, the program should quit.
@@ -160,6 +174,14 @@ func (o *appOptions) fixUp(errOutput io.ReadWriter) bool {
 		o.SYMMETRY = sudoku.SYMMETRY_HORIZONTAL
 	case "vertical":
 		o.SYMMETRY = sudoku.SYMMETRY_VERTICAL
+	case "rotational", "rotational-180":
+		o.SYMMETRY = sudoku.SYMMETRY_ROTATIONAL_180
+	case "rotational-90":
+		o.SYMMETRY = sudoku.SYMMETRY_ROTATIONAL_90
+	case "diagonal":
+		o.SYMMETRY = sudoku.SYMMETRY_DIAGONAL
+	case "anti-diagonal":
+		o.SYMMETRY = sudoku.SYMMETRY_ANTI_DIAGONAL
 	default:
 		logger.Println("Unknown symmetry flag: ", o.RAW_SYMMETRY)
 		return true
@@ -177,6 +199,11 @@ func (o *appOptions) fixUp(errOutput io.ReadWriter) bool {
 		logger.Println("Using difficulty max:", strconv.FormatFloat(vals.high, 'f', -1, 64), "min:", strconv.FormatFloat(vals.low, 'f', -1, 64))
 	}
 
+	if o.PER_PAGE < 1 {
+		logger.Println("Invalid per-page option:", o.PER_PAGE)
+		return true
+	}
+
 	o.CONVERTER = sdkconverter.Converters[sdkconverter.Format(o.PUZZLE_FORMAT)]
 
 	if o.CONVERTER == nil {
@@ -219,59 +246,37 @@ func process(options *appOptions, output io.ReadWriter, errOutput io.ReadWriter)
 	var bar *uiprogress.Bar
 
 	//TODO: do more useful / explanatory printing here.
-	if options.NUM > 1 && !options.NO_PROGRESS {
+	//We don't know ahead of time how many puzzles are in a file to solve.
+	if options.NUM > 1 && !options.NO_PROGRESS && options.PUZZLE_TO_SOLVE == "" {
 		options.progress = uiprogress.New()
 		options.progress.Out = errOutput
 		options.progress.Start()
 		bar = options.progress.AddBar(options.NUM).PrependElapsed().AppendCompleted()
 	}
 
-	var incomingPuzzles []sudoku.MutableGrid
+	var incomingPuzzles <-chan sudoku.MutableGrid
 
 	if options.PUZZLE_TO_SOLVE != "" {
 		//There are puzzles to load up.
+		incomingPuzzles = loadPuzzles(options, logger)
+	}
 
-		data, err := os.ReadFile(options.PUZZLE_TO_SOLVE)
-
-		if err != nil {
-			logger.Fatalln("Read error for specified file:", err)
-		}
-
-		var tempGrid sudoku.MutableGrid
-
-		var puzzleData []string
-
-		if options.CSV {
-			//Load up multiple.
-			csvReader := csv.NewReader(bytes.NewReader(data))
-			rows, err := csvReader.ReadAll()
-			if err != nil {
-				logger.Fatalln("The provided input CSV was not a valid CSV:", err)
-			}
-			for _, row := range rows {
-				puzzleData = append(puzzleData, row[0])
-			}
-		} else {
-			//Just load up a single file worth.
-			puzzleData = []string{string(data)}
-		}
-
-		for _, puzz := range puzzleData {
-
-			tempGrid = sudoku.NewGrid()
-
-			//TODO: shouldn't a load method have a way to say the string provided is invalid?
-			options.CONVERTER.Load(tempGrid, string(puzz))
+	var generatedPuzzles <-chan sudoku.MutableGrid
 
-			incomingPuzzles = append(incomingPuzzles, tempGrid)
+	if options.GENERATE && !options.FAKE_GENERATE {
+		gOptions := &sudoku.GenerationOptions{
+			Symmetry:           options.SYMMETRY,
+			SymmetryPercentage: options.SYMMETRY_PROPORTION,
+			MinFilledCells:     options.MIN_FILLED_CELLS,
+			Minimal:            options.MINIMAL,
 		}
-		//Tell the main loop how many puzzles to expect.
-		//TODO: this feels a bit like a hack, doesn't it? options.NUM is normally a user input value.
-		options.NUM = len(incomingPuzzles)
-
+		generatedPuzzles = generatePuzzles(options.NUM, options.MIN_DIFFICULTY, options.MAX_DIFFICULTY, gOptions, options.NO_CACHE, options.CONCURRENCY, logger)
 	}
 
-	for i := 0; i < options.NUM; i++ {
+	var booklet []*sudokurender.BookletPuzzle
+
+	//When solving puzzles from a file, keep going until they run out.
+	for i := 0; incomingPuzzles != nil || i < options.NUM; i++ {
 
 		//TODO: allow the type of symmetry to be configured.
 		if options.GENERATE {
@@ -279,17 +284,16 @@ func process(options *appOptions, output io.ReadWriter, errOutput io.ReadWriter)
 				grid = sudoku.NewGrid()
 				grid.LoadSDK(TEST_GRID)
 			} else {
-				gOptions := &sudoku.GenerationOptions{
-					Symmetry:           options.SYMMETRY,
-					SymmetryPercentage: options.SYMMETRY_PROPORTION,
-					MinFilledCells:     options.MIN_FILLED_CELLS,
-				}
-				grid = generatePuzzle(options.MIN_DIFFICULTY, options.MAX_DIFFICULTY, gOptions, options.NO_CACHE, logger)
+				grid = <-generatedPuzzles
 			}
 			writer.Write(options.CONVERTER.DataString(grid), "")
-		} else if len(incomingPuzzles)-1 >= i {
+		} else if incomingPuzzles != nil {
 			//Load up an inbound puzzle
-			grid = incomingPuzzles[i]
+			var ok bool
+			grid, ok = <-incomingPuzzles
+			if !ok {
+				break
+			}
 		}
 
 		if grid == nil {
@@ -297,6 +301,11 @@ func process(options *appOptions, output io.ReadWriter, errOutput io.ReadWriter)
 			logger.Fatalln("No grid loaded.")
 		}
 
+		if options.PDF != "" {
+			//Grab the puzzle now, before it's solved below.
+			booklet = append(booklet, bookletPuzzle(grid, len(booklet)+1))
+		}
+
 		//TODO: use of this option leads to a busy loop somewhere... Is it related to the generate-multiple-and-difficulty hang?
 
 		var directions *sudoku.SolveDirections
@@ -316,8 +325,25 @@ func process(options *appOptions, output io.ReadWriter, errOutput io.ReadWriter)
 			writer.Write(directions.Walkthrough(), "")
 		}
 		if options.PRINT_STATS {
+			stats := directions.Stats()
+			stats = append(stats, "Difficulty explanation:")
+			stats = append(stats, grid.DifficultyExplanation().Description()...)
 			writer.Write(strconv.FormatFloat(grid.Difficulty(), 'f', -1, 64),
-				strings.Join(directions.Stats(), "\n"))
+				strings.Join(stats, "\n"))
+		}
+		if options.HODOKU {
+			score, level := grid.HoDoKuRating()
+			writer.Write(level.String()+" ("+strconv.Itoa(score)+")", "")
+		}
+		if options.REDUNDANT {
+			redundant := grid.RedundantClues()
+			if !grid.HasSolution() || grid.HasMultipleSolutions() {
+				writer.Write("Puzzle doesn't have exactly one solution", "")
+			} else if len(redundant) == 0 {
+				writer.Write("No redundant clues", "")
+			} else {
+				writer.Write("Redundant clues: "+redundant.CellReferenceSlice().Description(), "")
+			}
 		}
 		//TODO: using the existence of options.PUZZLE_TO_SOLVE as the way to detect that
 		//we are working on an inbound puzzle seems a bit hackish.
@@ -333,6 +359,127 @@ func process(options *appOptions, output io.ReadWriter, errOutput io.ReadWriter)
 		}
 	}
 	writer.Done()
+
+	if options.PDF != "" {
+		writeBooklet(options, booklet, logger)
+	}
+}
+
+//difficultyName returns the name of the range in difficultyRanges that
+//difficulty falls in.
+func difficultyName(difficulty float64) string {
+	for _, name := range []string{"gentle", "easy", "medium", "tough"} {
+		if difficulty <= difficultyRanges[name].high {
+			return name
+		}
+	}
+	return "tough"
+}
+
+//bookletPuzzle returns grid, the number'th puzzle, as it should appear in
+//a -pdf booklet.
+func bookletPuzzle(grid sudoku.Grid, number int) *sudokurender.BookletPuzzle {
+	puzzle := grid.MutableCopy()
+
+	//Formats like sdk don't have locks, but every number in a fresh puzzle
+	//should be printed as a given.
+	anyLocked := false
+	for _, cell := range puzzle.Cells() {
+		if cell.Locked() {
+			anyLocked = true
+		}
+	}
+	if !anyLocked {
+		puzzle.LockFilledCells()
+	}
+
+	result := &sudokurender.BookletPuzzle{
+		Grid:  puzzle,
+		Title: "Puzzle " + strconv.Itoa(number),
+	}
+
+	//Only well-formed puzzles have an answer, or a meaningful difficulty.
+	if grid.HasSolution() && !grid.HasMultipleSolutions() {
+		result.Solution = puzzle.Solutions()[0]
+		difficulty := grid.Difficulty()
+		name := difficultyName(difficulty)
+		result.Label = strings.ToUpper(name[:1]) + name[1:] + " (" + strconv.FormatFloat(difficulty, 'f', 2, 64) + ")"
+	}
+
+	return result
+}
+
+func writeBooklet(options *appOptions, booklet []*sudokurender.BookletPuzzle, logger *log.Logger) {
+
+	file, err := os.Create(options.PDF)
+
+	if err != nil {
+		logger.Fatalln("Couldn't create PDF file:", err)
+	}
+
+	defer file.Close()
+
+	err = sudokurender.WriteBooklet(file, booklet, &sudokurender.BookletOptions{
+		Title:          options.TITLE,
+		PuzzlesPerPage: options.PER_PAGE,
+	})
+
+	if err != nil {
+		logger.Fatalln("Couldn't write PDF booklet:", err)
+	}
+}
+
+//loadPuzzles streams the puzzles in options.PUZZLE_TO_SOLVE, so that files
+//of many puzzles don't have to be read into memory all at once. Invalid
+//puzzles are logged and skipped.
+func loadPuzzles(options *appOptions, logger *log.Logger) <-chan sudoku.MutableGrid {
+
+	file, err := os.Open(options.PUZZLE_TO_SOLVE)
+
+	if err != nil {
+		logger.Fatalln("Read error for specified file:", err)
+	}
+
+	results := make(chan sudoku.MutableGrid)
+
+	go func() {
+		defer close(results)
+		defer file.Close()
+
+		if options.CSV {
+			//The first column of each row is a puzzle.
+			csvReader := csv.NewReader(file)
+			for {
+				row, err := csvReader.Read()
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					logger.Fatalln("The provided input CSV was not a valid CSV:", err)
+				}
+				grid := sudoku.NewGrid()
+				//TODO: shouldn't a load method have a way to say the string provided is invalid?
+				options.CONVERTER.Load(grid, row[0])
+				results <- grid
+			}
+		}
+
+		reader := sdkconverter.NewCollectionReader(file, sdkconverter.Format(options.PUZZLE_FORMAT))
+
+		for {
+			puzzle, err := reader.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				logger.Println("Skipping puzzle:", err)
+				continue
+			}
+			results <- puzzle.Grid
+		}
+	}()
+
+	return results
 }
 
 type StoredPuzzle struct {
@@ -340,8 +487,18 @@ type StoredPuzzle struct {
 	Difficulty float64
 	//In DOKU format
 	PuzzleData string
+	//The DataString of the puzzle's canonical form, so we never store two
+	//puzzles that are really the same.
+	Canonical string
 }
 
+//canonicalBucketName is the name of the bucket that maps the canonical form
+//of each stored puzzle to its key in the puzzle bucket.
+func canonicalBucketName() []byte {
+	return []byte(sudoku.DifficultyModelHash() + "-canonical")
+}
+
+//storePuzzle returns false if an equivalent puzzle was already stored.
 //TODO: take a sudoku.GenerationOptions to simplify signature
 func storePuzzle(dbName string, grid sudoku.Grid, difficulty float64, options *sudoku.GenerationOptions, logger *log.Logger) bool {
 
@@ -364,10 +521,18 @@ func storePuzzle(dbName string, grid sudoku.Grid, difficulty float64, options *s
 		logger.Fatalln("Puzzle didn't convert to doku format cleanly")
 	}
 
+	canonical := grid.Canonical().DataString()
+
+	//Record whether the puzzle actually is minimal, not whether it was asked
+	//to be, so vendPuzzle never hands out a non-minimal puzzle as minimal.
+	storedOptions := *options
+	storedOptions.Minimal = grid.IsMinimal()
+
 	puzzleObj := &StoredPuzzle{
-		Options:    options,
+		Options:    &storedOptions,
 		Difficulty: difficulty,
 		PuzzleData: puzzleData,
+		Canonical:  canonical,
 	}
 
 	jsonBlob, err := json.MarshalIndent(puzzleObj, "", "    ")
@@ -375,24 +540,44 @@ func storePuzzle(dbName string, grid sudoku.Grid, difficulty float64, options *s
 		logger.Fatalln("Json couldn't be marshalled", err)
 	}
 
+	stored := false
+
 	err = db.Update(func(tx *bolt.Tx) error {
 		bucket, err := tx.CreateBucketIfNotExists([]byte(sudoku.DifficultyModelHash()))
 		if err != nil {
 			return err
 		}
 
+		canonicalBucket, err := tx.CreateBucketIfNotExists(canonicalBucketName())
+		if err != nil {
+			return err
+		}
+
+		if canonicalBucket.Get([]byte(canonical)) != nil {
+			//Already have an equivalent puzzle; don't store it again.
+			return nil
+		}
+
 		id, err := bucket.NextSequence()
 
 		if err != nil {
 			return err
 		}
 
-		err = bucket.Put([]byte(strconv.Itoa(int(id))), []byte(jsonBlob))
+		key := []byte(strconv.Itoa(int(id)))
+
+		err = bucket.Put(key, []byte(jsonBlob))
+		if err != nil {
+			return err
+		}
+
+		err = canonicalBucket.Put([]byte(canonical), key)
 		if err != nil {
 			return err
 		}
 
 		//It worked
+		stored = true
 		return nil
 
 	})
@@ -402,7 +587,7 @@ func storePuzzle(dbName string, grid sudoku.Grid, difficulty float64, options *s
 		return false
 	}
 
-	return true
+	return stored
 }
 
 //TODO: take a sudoku.GenerationOptions to simplify signature
@@ -431,7 +616,13 @@ func vendPuzzle(dbName string, min float64, max float64, options *sudoku.Generat
 			return err
 		}
 
-		matchingPuzzles := make(map[string]string)
+		canonicalBucket, err := tx.CreateBucketIfNotExists(canonicalBucketName())
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+
+		matchingPuzzles := make(map[string]*StoredPuzzle)
 
 		bucket.ForEach(func(key, value []byte) error {
 			puzzleInfo := &StoredPuzzle{}
@@ -456,13 +647,18 @@ func vendPuzzle(dbName string, min float64, max float64, options *sudoku.Generat
 				return nil
 			}
 
+			if options.Minimal && !puzzleInfo.Options.Minimal {
+				//Doesn't match
+				return nil
+			}
+
 			if puzzleInfo.Difficulty > max || puzzleInfo.Difficulty < min {
 				//Doesn't match
 				return nil
 			}
 
 			//Does match!
-			matchingPuzzles[string(key)] = puzzleInfo.PuzzleData
+			matchingPuzzles[string(key)] = puzzleInfo
 			return nil
 
 		})
@@ -482,7 +678,7 @@ func vendPuzzle(dbName string, min float64, max float64, options *sudoku.Generat
 
 		key := keys[rand.Intn(len(keys))]
 
-		finalPuzzle = matchingPuzzles[key]
+		finalPuzzle = matchingPuzzles[key].PuzzleData
 
 		//Doesn't matter that much if we can't delete the key.
 		err = bucket.Delete([]byte(key))
@@ -492,6 +688,16 @@ func vendPuzzle(dbName string, min float64, max float64, options *sudoku.Generat
 			log.Println("Couldn't delete the key we picked:", err)
 		}
 
+		//Forget the puzzle's canonical form too, so an equivalent puzzle
+		//can be stored again later.
+		if canonical := matchingPuzzles[key].Canonical; canonical != "" {
+			err = canonicalBucket.Delete([]byte(canonical))
+
+			if err != nil {
+				log.Println("Couldn't delete the canonical form of the key we picked:", err)
+			}
+		}
+
 		return nil
 
 	})
@@ -507,39 +713,75 @@ func vendPuzzle(dbName string, min float64, max float64, options *sudoku.Generat
 	return grid
 }
 
-func generatePuzzle(min float64, max float64, options *sudoku.GenerationOptions, skipCache bool, logger *log.Logger) sudoku.MutableGrid {
-	var result sudoku.MutableGrid
+//generatePuzzles sends count puzzles within the given difficulty range on the
+//returned channel, first from the cache and then by generating up to
+//concurrency at a time. Generated puzzles outside the range are stored in
+//the cache for later. Puzzles equivalent to one already sent are skipped.
+func generatePuzzles(count int, min float64, max float64, options *sudoku.GenerationOptions, skipCache bool, concurrency int, logger *log.Logger) <-chan sudoku.MutableGrid {
+	result := make(chan sudoku.MutableGrid)
 
-	if !skipCache {
-		result = vendPuzzle(_STORED_PUZZLES_DB, min, max, options)
+	go func() {
+		defer close(result)
 
-		if result != nil {
-			logger.Println("Vending a puzzle from the cache.")
-			return result
-		}
-	}
+		remaining := count
 
-	//We'll have to generate one ourselves.
-	count := 0
-	for {
-		//The first time we don't bother saying what number attemp it is, because if the first run is likely to generate a useable puzzle it's just noise.
-		if count != 0 {
-			logger.Println("Attempt", count, "at generating puzzle.")
-		}
+		sent := make(map[string]bool)
 
-		result = sudoku.GenerateGrid(options)
+		//send sends grid unless an equivalent one was already sent, and
+		//returns whether it did.
+		send := func(grid sudoku.MutableGrid) bool {
+			canonical := grid.Canonical().DataString()
+			if sent[canonical] {
+				logger.Println("Skipping a puzzle equivalent to one already output.")
+				return false
+			}
+			sent[canonical] = true
+			result <- grid
+			remaining--
+			return true
+		}
 
-		difficulty := result.Difficulty()
+		if !skipCache {
+			for remaining > 0 {
+				grid := vendPuzzle(_STORED_PUZZLES_DB, min, max, options)
+				if grid == nil {
+					break
+				}
+				logger.Println("Vending a puzzle from the cache.")
+				send(grid)
+			}
+		}
 
-		if difficulty >= min && difficulty <= max {
-			return result
+		if remaining <= 0 {
+			return
 		}
 
-		logger.Println("Rejecting grid of difficulty", difficulty)
-		if storePuzzle(_STORED_PUZZLES_DB, result, difficulty, options, logger) {
-			logger.Println("Stored the puzzle for future use.")
+		//We'll have to generate the rest ourselves. GenerateGrid will search
+		//for a puzzle in the difficulty range, but it might give up and
+		//return the closest it found, so keep generating until we have
+		//enough.
+		targetOptions := *options
+		targetOptions.MinDifficulty = min
+		targetOptions.MaxDifficulty = max
+
+		done := make(chan bool)
+		defer close(done)
+
+		for generated := range sudoku.GenerateGrids(0, &targetOptions, concurrency, done) {
+			if generated.Difficulty >= min && generated.Difficulty <= max {
+				send(generated.Grid)
+				if remaining <= 0 {
+					return
+				}
+				continue
+			}
+
+			logger.Println("Rejecting grid of difficulty", generated.Difficulty)
+			if storePuzzle(_STORED_PUZZLES_DB, generated.Grid, generated.Difficulty, options, logger) {
+				logger.Println("Stored the puzzle for future use.")
+			}
 		}
+	}()
 
-		count++
-	}
+	return result
 }
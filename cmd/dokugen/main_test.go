@@ -8,6 +8,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -225,6 +226,67 @@ func TestPuzzleImport(t *testing.T) {
 	}
 }
 
+func TestPuzzleImportOtherFormats(t *testing.T) {
+	solvedRows := strings.Split(strings.Replace(SOLVED_TEST_GRID, "|", "", -1), "\n")
+
+	var solvedSS []string
+	for i, row := range solvedRows {
+		if i != 0 && i%3 == 0 {
+			solvedSS = append(solvedSS, "---+---+---")
+		}
+		solvedSS = append(solvedSS, row[0:3]+"|"+row[3:6]+"|"+row[6:9])
+	}
+
+	tests := []struct {
+		format   string
+		file     string
+		expected string
+	}{
+		{"line", "tests/puzzle_line.sdk", strings.Join(solvedRows, "")},
+		{"ss", "tests/puzzle_ss.ss", strings.Join(solvedSS, "\n")},
+	}
+
+	for _, test := range tests {
+		options := getDefaultOptions()
+
+		options.PUZZLE_TO_SOLVE = test.file
+		options.PUZZLE_FORMAT = test.format
+
+		expectUneventfulFixup(t, options)
+
+		output, errOutput := getOutput(options)
+
+		if errOutput != "" {
+			t.Error("For puzzle import with", test.format, "format expected no error output, got", errOutput)
+		}
+
+		if output != test.expected+"\n" {
+			t.Error("For puzzle import with", test.format, "format expected", test.expected+"\n", "got", output)
+		}
+	}
+}
+
+func TestPuzzleImportCollection(t *testing.T) {
+	options := getDefaultOptions()
+
+	options.PUZZLE_TO_SOLVE = "tests/puzzles_line.txt"
+	options.PUZZLE_FORMAT = "line"
+
+	expectUneventfulFixup(t, options)
+
+	output, errOutput := getOutput(options)
+
+	if !strings.Contains(errOutput, "Invalid puzzle starting on line 4") {
+		t.Error("For collection import expected the invalid puzzle to be logged, got", errOutput)
+	}
+
+	solved := strings.Replace(strings.Replace(SOLVED_TEST_GRID, "|", "", -1), "\n", "", -1)
+
+	if output != solved+"\n"+solved+"\n" {
+		t.Error("For collection import expected", solved+"\n"+solved+"\n", "got", output)
+	}
+}
+
 //TODO: test walkthrough
 
 func TestHelp(t *testing.T) {
@@ -286,6 +348,34 @@ func TestSingleGenerate(t *testing.T) {
 
 }
 
+func TestMinimalGenerate(t *testing.T) {
+	options := getDefaultOptions()
+
+	options.GENERATE = true
+	options.NUM = 1
+	options.MINIMAL = true
+	//Target a difficulty, to make sure that the search keeps the puzzle
+	//minimal.
+	options.MIN_DIFFICULTY = 0.05
+	options.MAX_DIFFICULTY = 0.95
+	options.NO_CACHE = true
+
+	expectUneventfulFixup(t, options)
+
+	output, errOutput := getOutput(options)
+
+	if errOutput != "" {
+		t.Error("Generating a minimal puzzle expected empty stderr, but got", errOutput)
+	}
+
+	grid := sudoku.NewGrid()
+	grid.LoadSDK(output)
+
+	if !grid.IsMinimal() {
+		t.Error("Output for minimal generate was not minimal. Redundant clues:", grid.RedundantClues(), output)
+	}
+}
+
 func TestMultiGenerate(t *testing.T) {
 	options := getDefaultOptions()
 
@@ -346,7 +436,9 @@ func TestPrintStats(t *testing.T) {
 		numLineRE("Avg Similarity", true) +
 		OUTPUT_DIVIDER_RE +
 		"(" + numLineRE(VARIANT_RE, false) + "){" + strconv.Itoa(len(sudoku.AllTechniqueVariants)) + "}" +
-		OUTPUT_DIVIDER_RE
+		OUTPUT_DIVIDER_RE +
+		`Difficulty explanation:\n` +
+		`(.+: -?` + FLOAT_RE + ` \(-?` + FLOAT_RE + ` \* -?` + FLOAT_RE + `\)\n)+`
 
 	if !regularExpressionMatch(re, output) {
 		t.Error("Output didn't match the expected RE for the output", output)
@@ -354,6 +446,79 @@ func TestPrintStats(t *testing.T) {
 
 }
 
+func TestHoDoKu(t *testing.T) {
+	options := getDefaultOptions()
+
+	options.GENERATE = true
+	options.NUM = 1
+	options.HODOKU = true
+	options.NO_PROGRESS = true
+	options.FAKE_GENERATE = true
+	options.NO_CACHE = true
+
+	expectUneventfulFixup(t, options)
+
+	output, _ := getOutput(options)
+
+	re := GRID_RE + `(Easy|Medium|Hard|Unfair|Extreme) \(` + INT_RE + `\)\n`
+
+	if !regularExpressionMatch(re, output) {
+		t.Error("Output didn't match the expected RE for the output", output)
+	}
+}
+
+func TestRedundant(t *testing.T) {
+	options := getDefaultOptions()
+
+	options.GENERATE = true
+	options.NUM = 1
+	options.REDUNDANT = true
+	options.NO_PROGRESS = true
+	options.FAKE_GENERATE = true
+	options.NO_CACHE = true
+
+	expectUneventfulFixup(t, options)
+
+	output, _ := getOutput(options)
+
+	re := GRID_RE + `(Redundant clues: .*\(\d,\d\).*|No redundant clues)\n`
+
+	if !regularExpressionMatch(re, output) {
+		t.Error("Output didn't match the expected RE for the output", output)
+	}
+}
+
+func TestSymmetryFlag(t *testing.T) {
+	tests := map[string]sudoku.SymmetryType{
+		"none":           sudoku.SYMMETRY_NONE,
+		"both":           sudoku.SYMMETRY_BOTH,
+		"Vertical":       sudoku.SYMMETRY_VERTICAL,
+		"rotational":     sudoku.SYMMETRY_ROTATIONAL_180,
+		"rotational-180": sudoku.SYMMETRY_ROTATIONAL_180,
+		"rotational-90":  sudoku.SYMMETRY_ROTATIONAL_90,
+		"diagonal":       sudoku.SYMMETRY_DIAGONAL,
+		"anti-diagonal":  sudoku.SYMMETRY_ANTI_DIAGONAL,
+	}
+
+	for flag, symmetry := range tests {
+		options := getDefaultOptions()
+		options.RAW_SYMMETRY = flag
+
+		expectUneventfulFixup(t, options)
+
+		if options.SYMMETRY != symmetry {
+			t.Error("Symmetry flag", flag, "gave wrong symmetry. Got", options.SYMMETRY, "expected", symmetry)
+		}
+	}
+
+	options := getDefaultOptions()
+	options.RAW_SYMMETRY = "sideways"
+
+	if !options.fixUp(&bytes.Buffer{}) {
+		t.Error("Invalid symmetry flag didn't cause fixup to fail")
+	}
+}
+
 func TestPuzzleFormat(t *testing.T) {
 	options := getDefaultOptions()
 
@@ -374,6 +539,52 @@ func TestPuzzleFormat(t *testing.T) {
 
 }
 
+func TestPDFBooklet(t *testing.T) {
+	options := getDefaultOptions()
+
+	dir, err := os.MkdirTemp("", "dokugen")
+
+	if err != nil {
+		t.Fatal("Couldn't create temp dir:", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	options.GENERATE = true
+	options.NUM = 3
+	options.NO_PROGRESS = true
+	options.FAKE_GENERATE = true
+	options.NO_CACHE = true
+	options.PER_PAGE = 2
+	options.PDF = filepath.Join(dir, "booklet.pdf")
+
+	expectUneventfulFixup(t, options)
+
+	output, _ := getOutput(options)
+
+	if !regularExpressionMatch(GRID_RE+GRID_RE+GRID_RE, output) {
+		t.Error("Making a booklet changed the output:", output)
+	}
+
+	data, err := os.ReadFile(options.PDF)
+
+	if err != nil {
+		t.Fatal("Booklet wasn't written:", err)
+	}
+
+	//Two pages of puzzles, and one of answers.
+	if !strings.HasPrefix(string(data), "%PDF-") || !strings.Contains(string(data), "/Count 3 >>") {
+		t.Error("Booklet wasn't a PDF with the right number of pages")
+	}
+
+	options = getDefaultOptions()
+	options.PER_PAGE = 0
+
+	if !options.fixUp(&bytes.Buffer{}) {
+		t.Error("Didn't get an error for an invalid per-page")
+	}
+}
+
 func TestInvalidPuzzleFormat(t *testing.T) {
 	options := getDefaultOptions()
 
@@ -422,7 +633,37 @@ func TestVendAndStorePuzzle(t *testing.T) {
 		t.Fatal("Able to vend from empty DB")
 	}
 
-	storePuzzle(TEST_DB_NAME, grid, 0.5, options, logger)
+	if !storePuzzle(TEST_DB_NAME, grid, 0.5, options, logger) {
+		t.Error("Couldn't store puzzle in empty DB")
+	}
+
+	transposed := sudoku.NewGrid()
+	for _, cell := range grid.Cells() {
+		transposed.MutableCell(cell.Col(), cell.Row()).SetNumber(cell.Number())
+	}
+
+	if storePuzzle(TEST_DB_NAME, transposed, 0.5, options, logger) {
+		t.Error("Stored a puzzle equivalent to one already stored")
+	}
+
+	relabel, err := sudoku.RelabelTransform([]int{3, 1, 2, 5, 4, 9, 8, 6, 7})
+	if err != nil {
+		t.Fatal("Couldn't create relabel transform:", err)
+	}
+	permuteBands, err := sudoku.PermuteBandsTransform([]int{2, 0, 1})
+	if err != nil {
+		t.Fatal("Couldn't create permute bands transform:", err)
+	}
+
+	isomorph := grid.Transform(sudoku.RotateTransform(1).Then(permuteBands).Then(relabel))
+
+	if isomorph.DataString() == grid.DataString() {
+		t.Fatal("Transformed puzzle was the same as the original")
+	}
+
+	if storePuzzle(TEST_DB_NAME, isomorph, 0.5, options, logger) {
+		t.Error("Stored a transformed puzzle equivalent to one already stored")
+	}
 
 	//Read from wrong difficulty number
 
@@ -448,6 +689,22 @@ func TestVendAndStorePuzzle(t *testing.T) {
 		t.Error("Got back a puzzle even though DB should be empty.")
 	}
 
+	if !storePuzzle(TEST_DB_NAME, isomorph, 0.5, options, logger) {
+		t.Error("Couldn't store a puzzle equivalent to one that was already vended")
+	}
+
+	//The stored puzzle isn't minimal, so it shouldn't be vended as minimal.
+	if isomorph.IsMinimal() {
+		t.Fatal("Test grid was unexpectedly minimal")
+	}
+
+	minimalOptions := *options
+	minimalOptions.Minimal = true
+
+	if vendPuzzle(TEST_DB_NAME, 0.4, 0.6, &minimalOptions) != nil {
+		t.Error("Vended a puzzle that isn't minimal when asked for a minimal one")
+	}
+
 }
 
 //Callers should call fixUpOptions after receiving this.
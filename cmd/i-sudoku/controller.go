@@ -16,6 +16,8 @@ import (
 
 const (
 	PUZZLE_SAVED_MESSAGE = "Puzzle saved to "
+	//How many of the signals that contributed most to difficulty to show.
+	_DIFFICULTY_FACTORS_TO_SHOW = 3
 )
 
 type mainController struct {
@@ -40,6 +42,7 @@ type mainController struct {
 	//Whether or not to handle inputs.
 	calculatingDifficulty bool
 	cachedDifficulty      float64
+	cachedExplanation     sudoku.DifficultyExplanation
 }
 
 const (
@@ -248,6 +251,7 @@ func (c *mainController) SetGrid(grid sudoku.MutableGrid) {
 		c.SetSelected(oldCell.InGrid(c.model.Grid()))
 	}
 	c.cachedDifficulty = 0.0
+	c.cachedExplanation = nil
 	c.snapshot = ""
 }
 
@@ -484,8 +488,23 @@ func (c *mainController) ShowDifficulty() {
 			//Grid has its own cached difficulty, but unfortunately it won't
 			//work for us because we're passing in a new grid :-(
 			c.cachedDifficulty = unfilledGrid.Difficulty()
+			c.cachedExplanation = unfilledGrid.DifficultyExplanation()
 		}
 		msg := "Grid Difficulty: {" + strconv.FormatFloat(c.cachedDifficulty, 'f', -1, 64) + "}"
+		msg += "\nBiggest factors:"
+		numFactors := 0
+		for _, item := range c.cachedExplanation {
+			if numFactors >= _DIFFICULTY_FACTORS_TO_SHOW {
+				break
+			}
+			//The constant is part of every puzzle's difficulty, so it doesn't
+			//explain anything about this one.
+			if item.Signal == "Constant" || item.Contribution == 0.0 {
+				continue
+			}
+			msg += "\n{" + item.Signal + "}: " + strconv.FormatFloat(item.Contribution, 'f', 4, 64)
+			numFactors++
+		}
 		c.SetConsoleMessage(msg, true)
 		//Clear the event loop to pump.
 		c.calculatingDifficulty = false
@@ -1,9 +1,33 @@
 package sudoku
 
 import (
+	"math"
 	"math/rand"
 )
 
+//The number of times to start over from a freshly generated grid when
+//searching for a grid within a difficulty range.
+const _MAX_DIFFICULTY_GENERATION_RESTARTS = 10
+
+//The number of moves to make from each freshly generated grid before giving
+//up and starting over.
+const _MAX_DIFFICULTY_GENERATION_STEPS = 20
+
+//The number of different moves to try out at each step, picking the one that
+//gets closest to the target difficulty.
+const _DIFFICULTY_GENERATION_CANDIDATES = 3
+
+//generationDifficulty returns the difficulty of a grid during generation.
+//When accurate is false it only needs to be a rough estimate.
+func generationDifficulty(grid MutableGrid, accurate bool) float64 {
+	if accurate {
+		//Difficulty caches the value, so callers who check the difficulty of
+		//the returned grid get it for free.
+		return grid.Difficulty()
+	}
+	return calcluateGridDifficulty(grid, false)
+}
+
 //GenerationOptions provides configuration options for generating a sudoku puzzle.
 type GenerationOptions struct {
 	//symmetrty and symmetryType control the aesthetics of the generated grid. symmetryPercentage
@@ -15,6 +39,49 @@ type GenerationOptions struct {
 	//more filled cells. A value of DIM * DIM - 1, for example, would return an extremely trivial
 	//puzzle.
 	MinFilledCells int
+	//MinDifficulty and MaxDifficulty are the range of difficulties (see
+	//Grid.Difficulty) the generated puzzle should fall within. A
+	//MaxDifficulty of 0.0 is treated as 1.0. If no puzzle in the range can
+	//be found the closest one found will be returned, so callers who need a
+	//guarantee should check the result's Difficulty.
+	//
+	//Targeting a difficulty makes generation much slower. Each candidate
+	//grid's difficulty is estimated by human solving it 10 times, and a
+	//grid whose estimate is in range is confirmed with Grid.Difficulty,
+	//which can take up to 50 times that. When the first grid is already in
+	//range (more likely the wider the range) that's one estimate and one
+	//confirmation, typically tens of seconds. A narrow range can take up to
+	//600 estimates (10 restarts of 20 moves, trying 3 candidates each),
+	//which can be many minutes.
+	MinDifficulty float64
+	MaxDifficulty float64
+	//Minimal, if true, guarantees that every clue in the generated puzzle is
+	//necessary (see Grid.IsMinimal), even if that means breaking symmetry or
+	//leaving fewer than MinFilledCells filled. When targeting a difficulty,
+	//only minimal puzzles are considered, which makes easy difficulty ranges
+	//harder to reach. It can't be guaranteed with RequiredTechniques (where
+	//every clue is instead necessary for the puzzle to be solved with the
+	//allowed techniques).
+	Minimal bool
+	//Pattern, if provided, is exactly which cells should be filled in the
+	//generated puzzle, for example to make a puzzle whose clues form a
+	//shape. See ParsePattern and SymmetricalPattern. When set, every other
+	//option is ignored, and patterns that are too sparse may not have any
+	//puzzle with a unique solution.
+	Pattern CellRefSlice
+	//RequiredTechniques, if provided, are techniques that the generated
+	//puzzle cannot be solved without. Each must be one of Techniques. When
+	//set, MinDifficulty and MaxDifficulty are ignored. See
+	//GenerateGridWithTechniques.
+	RequiredTechniques []SolveTechnique `json:"-"`
+	//MaxTechnique is the hardest technique (that is, the latest one in
+	//Techniques) the generated puzzle may need. If nil, the hardest of
+	//RequiredTechniques is used. Ignored if RequiredTechniques is empty.
+	MaxTechnique SolveTechnique `json:"-"`
+	//difficulty, if provided, is used instead of generationDifficulty to
+	//judge the difficulty of grids during generation. Tests use it to
+	//substitute something much faster.
+	difficulty func(grid MutableGrid, accurate bool) float64
 }
 
 //DefaultGenerationOptions returns a GenerationOptions object configured to
@@ -24,9 +91,51 @@ func DefaultGenerationOptions() *GenerationOptions {
 	result.Symmetry = SYMMETRY_VERTICAL
 	result.SymmetryPercentage = 0.7
 	result.MinFilledCells = 0
+	result.MinDifficulty = 0.0
+	result.MaxDifficulty = 1.0
+	result.Minimal = false
 	return result
 }
 
+//gridDifficulty returns the difficulty of a grid during generation with
+//these options; see generationDifficulty. self may be nil.
+func (self *GenerationOptions) gridDifficulty(grid MutableGrid, accurate bool) float64 {
+	if self != nil && self.difficulty != nil {
+		return self.difficulty(grid, accurate)
+	}
+	return generationDifficulty(grid, accurate)
+}
+
+//difficultyRange returns the min and max difficulty to target, with the
+//defaults filled in.
+func (self *GenerationOptions) difficultyRange() (float64, float64) {
+	max := self.MaxDifficulty
+	if max <= 0.0 {
+		max = 1.0
+	}
+	return self.MinDifficulty, max
+}
+
+//targetsDifficulty returns true if the options restrict the difficulty of
+//the generated puzzle at all.
+func (self *GenerationOptions) targetsDifficulty() bool {
+	min, max := self.difficultyRange()
+	return min > 0.0 || max < 1.0
+}
+
+//difficultyDistance returns how far the given difficulty is from the target
+//range, or 0.0 if it's within it.
+func (self *GenerationOptions) difficultyDistance(difficulty float64) float64 {
+	min, max := self.difficultyRange()
+	if difficulty < min {
+		return min - difficulty
+	}
+	if difficulty > max {
+		return difficulty - max
+	}
+	return 0.0
+}
+
 func (self *mutableGridImpl) Fill() bool {
 
 	solutions := nOrFewerSolutions(self, 1)
@@ -48,22 +157,48 @@ func (self *mutableGridImpl) Fill() bool {
 //filling of the grid, then iteratively removes cells until just before the
 //grid begins having multiple solutions. The result is a grid that has a
 //single valid solution but many of its cells unfilled. Pass nil for options
-//to use reasonable defaults. GenerateGrid doesn't currently give any way to
-//define the desired difficulty; the best option is to repeatedly generate
-//puzzles until you find one that matches your desired difficulty. cmd/dokugen
-//applies this technique.
+//to use reasonable defaults. If options specifies a MinDifficulty or
+//MaxDifficulty, GenerateGrid then repeatedly adds or removes clues, keeping
+//whichever changes move the difficulty closest to the target range, until
+//the puzzle is within it. If options specifies RequiredTechniques, this is
+//equivalent to GenerateGridWithTechniques, and will return nil if no
+//matching puzzle could be generated. If options specifies a Pattern,
+//GenerateGrid instead searches for numbers for the cells in the pattern
+//that give a puzzle with a unique solution, returning nil if it can't find
+//one.
 func GenerateGrid(options *GenerationOptions) MutableGrid {
 
 	if options == nil {
 		options = DefaultGenerationOptions()
 	}
 
-	grid := NewGrid()
-	//Do a random fill of the grid
-	grid.Fill()
+	var grid MutableGrid
+
+	if len(options.Pattern) > 0 {
+		grid = generateGridWithPattern(options.Pattern)
+		if grid == nil {
+			return nil
+		}
+	} else if len(options.RequiredTechniques) > 0 {
+		grid, _, _ = GenerateGridWithTechniques(options)
+		//GenerateGridWithTechniques already locked the grid.
+		return grid
+	} else if options.targetsDifficulty() {
+		grid = generateGridWithDifficulty(options)
+	} else {
+		grid = generateUnlockedGrid(options)
+	}
+
+	grid.LockFilledCells()
 
+	return grid
+}
+
+//clampedSymmetryPercentage returns the options' SymmetryPercentage within
+//the legal range.
+func (self *GenerationOptions) clampedSymmetryPercentage() float64 {
 	//Make a copy so we don't mutate the passed in dict
-	symmetryPercentage := options.SymmetryPercentage
+	symmetryPercentage := self.SymmetryPercentage
 
 	//Make sure symmetry percentage is within the legal range.
 	if symmetryPercentage < 0.0 {
@@ -72,6 +207,27 @@ func GenerateGrid(options *GenerationOptions) MutableGrid {
 	if symmetryPercentage > 1.0 {
 		symmetryPercentage = 1.0
 	}
+	return symmetryPercentage
+}
+
+//generateUnlockedGrid does the work of GenerateGrid, without targeting a
+//difficulty or locking the filled cells.
+func generateUnlockedGrid(options *GenerationOptions) MutableGrid {
+	return generateUnlockedGridWithCheck(options, func(grid MutableGrid) bool {
+		return !grid.HasMultipleSolutions()
+	})
+}
+
+//generateUnlockedGridWithCheck fills a grid and then removes as many cells
+//as it can, putting back any whose removal makes valid return false.
+//valid must at least ensure the grid has a single solution.
+func generateUnlockedGridWithCheck(options *GenerationOptions, valid func(grid MutableGrid) bool) MutableGrid {
+
+	grid := NewGrid()
+	//Do a random fill of the grid
+	grid.Fill()
+
+	symmetryPercentage := options.clampedSymmetryPercentage()
 
 	originalCells := grid.MutableCells()
 	cells := make(MutableCellSlice, len(originalCells))
@@ -87,52 +243,301 @@ func GenerateGrid(options *GenerationOptions) MutableGrid {
 			continue
 		}
 
-		var otherNum int
-		var otherCell MutableCell
+		var otherCells MutableCellSlice
 
 		if rand.Float64() < symmetryPercentage {
 
-			//Pick a symmetrical partner for symmetryPercentage number of cells.
-			otherCell = cell.MutableSymmetricalPartner(options.Symmetry)
-
-			if otherCell != nil {
+			//Pick symmetrical partners for symmetryPercentage number of cells.
+			for _, otherCell := range mutableSymmetricalOrbit(cell, options.Symmetry) {
 				if otherCell.Number() == 0 {
 					//We must have already un-filled it as a primary cell.
 					//If we were to unfill this, we could get in a weird state where
 					//we get multiple solutions without noticing (which caused bug #134).
 					//So pretend like we didn't draw one.
-					otherCell = nil
-				} else {
-					otherNum = otherCell.Number()
+					continue
 				}
+				otherCells = append(otherCells, otherCell)
 			}
 		}
 
-		numCellsToFillThisStep := 1
-		if otherCell != nil {
-			numCellsToFillThisStep = 2
-		}
+		numCellsToFillThisStep := 1 + len(otherCells)
 
 		if grid.numFilledCells()-numCellsToFillThisStep < options.MinFilledCells {
 			//Doing this step would leave us with too few cells filled. Finish.
 			break
 		}
 
+		otherNums := make([]int, len(otherCells))
+
 		//Unfill it.
 		cell.SetNumber(0)
-		if otherCell != nil {
+		for i, otherCell := range otherCells {
+			otherNums[i] = otherCell.Number()
 			otherCell.SetNumber(0)
 		}
-		if grid.HasMultipleSolutions() {
+		if !valid(grid) {
 			//Put it back in.
 			cell.SetNumber(num)
-			if otherCell != nil {
-				otherCell.SetNumber(otherNum)
+			for i, otherCell := range otherCells {
+				otherCell.SetNumber(otherNums[i])
 			}
 		}
 	}
 
-	grid.LockFilledCells()
+	if options.Minimal {
+		//Removing cells in symmetrical pairs, or stopping early because of
+		//MinFilledCells, can leave cells that could be removed on their
+		//own.
+		removeRedundantClues(cells, grid, valid)
+	}
 
 	return grid
 }
+
+//removeRedundantClues tries removing each of cells, in order, from grid,
+//putting back any whose removal makes valid return false. Since removing
+//cells can only ever make other cells more necessary, a single pass over
+//every filled cell leaves the grid minimal.
+func removeRedundantClues(cells MutableCellSlice, grid MutableGrid, valid func(grid MutableGrid) bool) {
+	for _, cell := range cells {
+		num := cell.Number()
+		if num == 0 {
+			continue
+		}
+		cell.SetNumber(0)
+		if !valid(grid) {
+			cell.SetNumber(num)
+		}
+	}
+}
+
+//generateGridWithDifficulty generates grids and then hill-climbs towards
+//the difficulty range in options by adding clues (to make the puzzle easier)
+//or removing them (to make it harder). Each move is judged with a cheap
+//estimate of difficulty; once the estimate is in range it is confirmed with
+//an accurate calculation. If no grid is confirmed to be in range, the one
+//that got closest is returned.
+func generateGridWithDifficulty(options *GenerationOptions) MutableGrid {
+
+	//best is the closest grid whose difficulty was accurately calculated;
+	//closest is the closest grid by estimated difficulty.
+	var best, closest MutableGrid
+	bestDistance := math.Inf(1)
+	closestDistance := math.Inf(1)
+
+	for restart := 0; restart < _MAX_DIFFICULTY_GENERATION_RESTARTS; restart++ {
+
+		grid := generateUnlockedGrid(options)
+
+		solution := grid.MutableCopy()
+		solution.Fill()
+
+		difficulty := options.gridDifficulty(grid, false)
+
+		for step := 0; ; step++ {
+
+			if options.difficultyDistance(difficulty) == 0.0 {
+				//The estimate says we're in range; make sure.
+				difficulty = options.gridDifficulty(grid, true)
+				distance := options.difficultyDistance(difficulty)
+				if distance == 0.0 {
+					return grid
+				}
+				if distance < bestDistance {
+					best = grid
+					bestDistance = distance
+				}
+			}
+
+			if step >= _MAX_DIFFICULTY_GENERATION_STEPS {
+				break
+			}
+
+			min, _ := options.difficultyRange()
+			harder := difficulty < min
+
+			var nextGrid MutableGrid
+			nextDifficulty := difficulty
+			nextDistance := options.difficultyDistance(difficulty)
+
+			for i := 0; i < _DIFFICULTY_GENERATION_CANDIDATES; i++ {
+				candidate := difficultyNeighbor(grid, solution, harder, options)
+				if candidate == nil {
+					continue
+				}
+				candidateDifficulty := options.gridDifficulty(candidate, false)
+				candidateDistance := options.difficultyDistance(candidateDifficulty)
+				if candidateDistance < nextDistance {
+					nextGrid = candidate
+					nextDifficulty = candidateDifficulty
+					nextDistance = candidateDistance
+				}
+			}
+
+			if nextGrid == nil {
+				//We're stuck at a local optimum; start over.
+				break
+			}
+
+			grid = nextGrid
+			difficulty = nextDifficulty
+		}
+
+		if distance := options.difficultyDistance(difficulty); distance < closestDistance {
+			closest = grid
+			closestDistance = distance
+		}
+	}
+
+	if best == nil {
+		//No grid was close enough to confirm, so the closest estimate will
+		//have to do.
+		return closest
+	}
+
+	return best
+}
+
+//difficultyNeighbor returns a copy of grid with a small change that is
+//likely to make it harder (removing clues) or easier (adding clues from
+//solution), while keeping a unique solution. If the grid is already minimal
+//and harder is true, it swaps a clue for a different one instead. If
+//options.Minimal is set, any clues the change made redundant are removed
+//afterwards, so a minimal grid stays minimal. Returns nil if no such change
+//could be found.
+func difficultyNeighbor(grid MutableGrid, solution Grid, harder bool, options *GenerationOptions) MutableGrid {
+	result := difficultyNeighborImpl(grid, solution, harder, options)
+
+	if result == nil || !options.Minimal {
+		return result
+	}
+
+	//Try removing the clues that were just added last, so the change
+	//sticks if there's any way for it to.
+	var cells, added MutableCellSlice
+	allCells := result.MutableCells()
+	for _, i := range rand.Perm(len(allCells)) {
+		cell := allCells[i]
+		if cell.Number() == 0 {
+			continue
+		}
+		if cell.InGrid(grid).Number() == 0 {
+			added = append(added, cell)
+		} else {
+			cells = append(cells, cell)
+		}
+	}
+
+	removeRedundantClues(append(cells, added...), result, func(grid MutableGrid) bool {
+		return !grid.HasMultipleSolutions()
+	})
+
+	return result
+}
+
+//difficultyNeighborImpl does the work of difficultyNeighbor, without keeping
+//the grid minimal.
+func difficultyNeighborImpl(grid MutableGrid, solution Grid, harder bool, options *GenerationOptions) MutableGrid {
+	result := grid.MutableCopy()
+
+	symmetryPercentage := options.clampedSymmetryPercentage()
+
+	//partnersFor returns the symmetrical partners to change along with cell,
+	//if we should change any at all. Only partners that are filled (or not)
+	//the same as the cell are returned.
+	partnersFor := func(cell MutableCell, filled bool) MutableCellSlice {
+		if rand.Float64() >= symmetryPercentage {
+			return nil
+		}
+		var partners MutableCellSlice
+		for _, partner := range mutableSymmetricalOrbit(cell, options.Symmetry) {
+			if (partner.Number() != 0) == filled {
+				partners = append(partners, partner)
+			}
+		}
+		return partners
+	}
+
+	addClue := func() MutableCell {
+		var unfilled MutableCellSlice
+		for _, cell := range result.MutableCells() {
+			if cell.Number() == 0 {
+				unfilled = append(unfilled, cell)
+			}
+		}
+		if len(unfilled) == 0 {
+			return nil
+		}
+		cell := unfilled[rand.Intn(len(unfilled))]
+		cell.SetNumber(cell.InGrid(solution).Number())
+		for _, partner := range partnersFor(cell, false) {
+			partner.SetNumber(partner.InGrid(solution).Number())
+		}
+		return cell
+	}
+
+	//removeClue removes a clue other than the given cell, returning false
+	//if none could be removed without losing the unique solution.
+	removeClue := func(skip MutableCell) bool {
+		cells := result.MutableCells()
+		for _, i := range rand.Perm(len(cells)) {
+			cell := cells[i]
+			if cell.Number() == 0 || cell == skip {
+				continue
+			}
+
+			var partners MutableCellSlice
+			for _, partner := range partnersFor(cell, true) {
+				if partner != skip {
+					partners = append(partners, partner)
+				}
+			}
+
+			if result.numFilledCells()-(1+len(partners)) < options.MinFilledCells {
+				continue
+			}
+
+			num := cell.Number()
+			cell.SetNumber(0)
+			partnerNums := make([]int, len(partners))
+			for i, partner := range partners {
+				partnerNums[i] = partner.Number()
+				partner.SetNumber(0)
+			}
+			if !result.HasMultipleSolutions() {
+				return true
+			}
+			cell.SetNumber(num)
+			for i, partner := range partners {
+				partner.SetNumber(partnerNums[i])
+			}
+		}
+		return false
+	}
+
+	if !harder {
+		if addClue() == nil {
+			return nil
+		}
+		return result
+	}
+
+	//A minimal grid has no clue that can be removed on its own, so don't
+	//bother trying.
+	if !options.Minimal && removeClue(nil) {
+		return result
+	}
+
+	//The grid is minimal, so swap in a new clue and remove two others.
+	added := addClue()
+	if added == nil {
+		return nil
+	}
+	if !removeClue(added) {
+		return nil
+	}
+	//Removing a second clue is a bonus; the swap alone changes the puzzle.
+	removeClue(added)
+
+	return result
+}
@@ -0,0 +1,75 @@
+package sudoku
+
+import (
+	"sync"
+)
+
+//GeneratedGrid is a grid generated by GenerateGrids, along with its
+//difficulty.
+type GeneratedGrid struct {
+	Grid       MutableGrid
+	Difficulty float64
+}
+
+//GenerateGrids generates count grids with GenerateGrid and calculates the
+//Difficulty of each, running up to concurrency of them at once. Each result
+//is sent on the returned channel as soon as it is done, so they may arrive
+//in any order. The channel is closed once every grid has been sent. If
+//count is less than 1, GenerateGrids will keep generating grids until done
+//is closed. Closing done cancels generation: no new grids will be started,
+//and any in progress will be thrown away when they finish. Grids that
+//GenerateGrid fails to generate (see GenerationOptions.Pattern and
+//RequiredTechniques) are skipped, so fewer than count may be sent.
+func GenerateGrids(count int, options *GenerationOptions, concurrency int, done <-chan bool) <-chan *GeneratedGrid {
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make(chan *GeneratedGrid)
+
+	//Each item on jobs is permission to generate one more grid.
+	jobs := make(chan bool)
+
+	go func() {
+		defer close(jobs)
+		for i := 0; count < 1 || i < count; i++ {
+			select {
+			case jobs <- true:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				grid := GenerateGrid(options)
+				if grid == nil {
+					continue
+				}
+				result := &GeneratedGrid{
+					Grid:       grid,
+					Difficulty: options.gridDifficulty(grid, true),
+				}
+				select {
+				case results <- result:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
@@ -0,0 +1,63 @@
+package sudoku
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGenerateGrids(t *testing.T) {
+	options := DefaultGenerationOptions()
+	options.difficulty = fakeGenerationDifficulty
+
+	var results []*GeneratedGrid
+
+	for result := range GenerateGrids(4, options, 2, nil) {
+		results = append(results, result)
+	}
+
+	if len(results) != 4 {
+		t.Fatal("Got wrong number of results. Got", len(results), "expected 4")
+	}
+
+	for _, result := range results {
+		if result.Grid == nil {
+			t.Fatal("Got nil grid")
+		}
+		if result.Grid.HasMultipleSolutions() {
+			t.Error("Generated grid had multiple solutions")
+		}
+		if result.Difficulty != fakeGenerationDifficulty(result.Grid, true) {
+			t.Error("Result had wrong difficulty")
+		}
+	}
+}
+
+func TestGenerateGridsCancel(t *testing.T) {
+	options := DefaultGenerationOptions()
+	options.difficulty = fakeGenerationDifficulty
+
+	done := make(chan bool)
+
+	results := GenerateGrids(0, options, 3, done)
+
+	for i := 0; i < 5; i++ {
+		if result := <-results; result == nil {
+			t.Fatal("Unbounded generation stopped early")
+		}
+	}
+
+	close(done)
+
+	timeout := time.After(10 * time.Second)
+
+	for {
+		select {
+		case _, ok := <-results:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Results channel wasn't closed after cancelling")
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package sudoku
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+//The number of times to start over from a new solution grid before giving
+//up on generating a puzzle with a given pattern.
+const _MAX_PATTERN_GENERATION_RESTARTS = 10
+
+//The number of clues to try changing, starting from each solution grid,
+//before starting over.
+const _MAX_PATTERN_GENERATION_STEPS = 300
+
+//How many solutions to look for when judging whether changing a clue made
+//a puzzle closer to having a unique solution. Puzzles with at least this
+//many solutions are considered equally far away.
+const _PATTERN_GENERATION_SOLUTION_LIMIT = 50
+
+//No puzzle with fewer clues than this has a unique solution.
+const _MIN_UNIQUE_PUZZLE_CLUES = 17
+
+//ParsePattern parses a pattern of cells for GenerationOptions.Pattern. The
+//pattern should have DIM lines of DIM characters each, where '.' or '0'
+//represents a cell that should be unfilled and any other character (for
+//example 'X' or a digit) represents a cell that should be a clue. Blank
+//lines and whitespace at the ends of lines are ignored.
+func ParsePattern(data string) (CellRefSlice, error) {
+	var result CellRefSlice
+
+	row := 0
+
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if row >= DIM {
+			return nil, errors.New("Pattern has too many rows")
+		}
+		if len(line) != DIM {
+			return nil, errors.New("Pattern row has the wrong number of cells: " + line)
+		}
+		for col, char := range line {
+			if char == '.' || char == '0' {
+				continue
+			}
+			result = append(result, CellRef{row, col})
+		}
+		row++
+	}
+
+	if row != DIM {
+		return nil, errors.New("Pattern has too few rows")
+	}
+
+	return result, nil
+}
+
+//SymmetricalPattern returns a copy of pattern with the symmetrical partners
+//(see Cell.SymmetricalPartner) of every cell added, so that the pattern is
+//perfectly symmetrical. If symmetry is SYMMETRY_ANY, a single type of
+//symmetry is chosen at random for the whole pattern. The result is sorted
+//by row, then column.
+func SymmetricalPattern(pattern CellRefSlice, symmetry SymmetryType) CellRefSlice {
+
+	symmetry = concreteSymmetry(symmetry)
+
+	grid := NewGrid()
+
+	cells := make(cellSet)
+
+	for _, ref := range pattern {
+		cell := grid.MutableCell(ref.Row, ref.Col)
+		if cell == nil {
+			continue
+		}
+		cells[ref] = true
+		for _, partner := range mutableSymmetricalOrbit(cell, symmetry) {
+			cells[partner.Reference()] = true
+		}
+	}
+
+	var result CellRefSlice
+
+	for ref := range cells {
+		result = append(result, ref)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Row != result[j].Row {
+			return result[i].Row < result[j].Row
+		}
+		return result[i].Col < result[j].Col
+	})
+
+	return result
+}
+
+//generateGridWithPattern searches for digits for the cells in pattern that
+//give a puzzle with a unique solution. It starts with the clues of a random
+//solution grid. While the puzzle has more than one solution, it takes two of
+//them and picks a clue that shares a group with a cell where they differ,
+//since only a change there can rule one of them out. It tries every other
+//number that fits in that clue, and keeps the one that leaves the fewest
+//solutions, as long as that isn't more than before. Returns nil if no
+//puzzle was found.
+func generateGridWithPattern(pattern CellRefSlice) MutableGrid {
+
+	inPattern := make(cellSet)
+
+	for _, ref := range pattern {
+		if ref.Row < 0 || ref.Row >= DIM || ref.Col < 0 || ref.Col >= DIM {
+			return nil
+		}
+		inPattern[ref] = true
+	}
+
+	if len(inPattern) < _MIN_UNIQUE_PUZZLE_CLUES {
+		return nil
+	}
+
+	for restart := 0; restart < _MAX_PATTERN_GENERATION_RESTARTS; restart++ {
+		solution := NewGrid()
+		solution.Fill()
+
+		grid := patternClues(solution, inPattern)
+		solutions := nOrFewerSolutions(grid, _PATTERN_GENERATION_SOLUTION_LIMIT)
+
+		for step := 0; step < _MAX_PATTERN_GENERATION_STEPS; step++ {
+			if len(solutions) == 1 {
+				return grid
+			}
+
+			var candidates MutableCellSlice
+			for _, cell := range grid.MutableCells() {
+				if !inPattern[cell.Reference()] {
+					continue
+				}
+				for _, neighbor := range cell.Neighbors() {
+					if neighbor.InGrid(solutions[0]).Number() != neighbor.InGrid(solutions[1]).Number() {
+						candidates = append(candidates, cell)
+						break
+					}
+				}
+			}
+
+			if len(candidates) == 0 {
+				break
+			}
+
+			cell := candidates[rand.Intn(len(candidates))]
+
+			trial := grid.MutableCopy()
+			trialCell := cell.MutableInGrid(trial)
+			trialCell.SetNumber(0)
+
+			possibilities := trialCell.Possibilities()
+
+			for _, i := range rand.Perm(len(possibilities)) {
+				num := possibilities[i]
+				if num == cell.Number() {
+					continue
+				}
+				trialCell.SetNumber(num)
+				trialSolutions := nOrFewerSolutions(trial, _PATTERN_GENERATION_SOLUTION_LIMIT)
+				if len(trialSolutions) != 0 && len(trialSolutions) <= len(solutions) {
+					grid = trial.MutableCopy()
+					solutions = trialSolutions
+				}
+				trialCell.SetNumber(0)
+			}
+		}
+	}
+
+	return nil
+}
+
+//patternClues returns a copy of solution with only the cells in pattern
+//filled.
+func patternClues(solution Grid, inPattern cellSet) MutableGrid {
+	result := NewGrid()
+	for _, cell := range solution.Cells() {
+		if inPattern[cell.Reference()] {
+			cell.MutableInGrid(result).SetNumber(cell.Number())
+		}
+	}
+	return result
+}
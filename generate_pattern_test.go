@@ -0,0 +1,190 @@
+package sudoku
+
+import (
+	"reflect"
+	"testing"
+)
+
+//A diamond-ish pattern with 34 clues.
+const TEST_PATTERN = `
+X.X...X.X
+.X.X.X.X.
+X..X.X..X
+.X..X..X.
+X.X...X.X
+.X..X..X.
+X..X.X..X
+.X.X.X.X.
+X.X...X.X
+`
+
+func TestParsePattern(t *testing.T) {
+	pattern, err := ParsePattern(TEST_PATTERN)
+
+	if err != nil {
+		t.Fatal("Couldn't parse pattern:", err)
+	}
+
+	if len(pattern) != 34 {
+		t.Error("Pattern had wrong number of cells:", len(pattern))
+	}
+
+	if pattern[0] != (CellRef{0, 0}) {
+		t.Error("Pattern had wrong first cell:", pattern[0])
+	}
+
+	if pattern[len(pattern)-1] != (CellRef{8, 8}) {
+		t.Error("Pattern had wrong last cell:", pattern[len(pattern)-1])
+	}
+
+	for _, invalid := range []string{
+		"",
+		"XXX\nXXX\nXXX",
+		TEST_PATTERN + "XXXXXXXXX\n",
+		TEST_PATTERN[:len(TEST_PATTERN)-11],
+	} {
+		if _, err := ParsePattern(invalid); err == nil {
+			t.Error("Didn't get error parsing invalid pattern", invalid)
+		}
+	}
+}
+
+func TestSymmetricalPattern(t *testing.T) {
+	pattern := CellRefSlice{
+		{0, 0},
+		{4, 4},
+		{0, 8},
+		{2, 1},
+	}
+
+	result := SymmetricalPattern(pattern, SYMMETRY_VERTICAL)
+
+	golden := CellRefSlice{
+		{0, 0},
+		{0, 8},
+		{2, 1},
+		{2, 7},
+		{4, 4},
+	}
+
+	if !reflect.DeepEqual(result, golden) {
+		t.Error("Got wrong vertical symmetrical pattern. Got", result, "expected", golden)
+	}
+
+	result = SymmetricalPattern(pattern, SYMMETRY_BOTH)
+
+	golden = CellRefSlice{
+		{0, 0},
+		{0, 8},
+		{2, 1},
+		{4, 4},
+		{6, 7},
+		{8, 0},
+		{8, 8},
+	}
+
+	if !reflect.DeepEqual(result, golden) {
+		t.Error("Got wrong both symmetrical pattern. Got", result, "expected", golden)
+	}
+
+	result = SymmetricalPattern(CellRefSlice{{0, 1}}, SYMMETRY_ROTATIONAL_90)
+
+	golden = CellRefSlice{
+		{0, 1},
+		{1, 8},
+		{7, 0},
+		{8, 7},
+	}
+
+	if !reflect.DeepEqual(result, golden) {
+		t.Error("Got wrong rotational 90 symmetrical pattern. Got", result, "expected", golden)
+	}
+
+	result = SymmetricalPattern(pattern, SYMMETRY_NONE)
+
+	if len(result) != len(pattern) {
+		t.Error("Pattern with no symmetry changed size:", result)
+	}
+}
+
+func TestGeneratePattern(t *testing.T) {
+	pattern, _ := ParsePattern(TEST_PATTERN)
+
+	grid := GenerateGrid(&GenerationOptions{
+		Pattern: pattern,
+	})
+
+	if grid == nil {
+		t.Fatal("Couldn't generate grid with pattern")
+	}
+
+	if grid.HasMultipleSolutions() {
+		t.Error("Generated grid had multiple solutions")
+	}
+
+	inPattern := make(cellSet)
+	for _, ref := range pattern {
+		inPattern[ref] = true
+	}
+
+	for _, cell := range grid.Cells() {
+		if inPattern[cell.Reference()] {
+			if cell.Number() == 0 || !cell.Locked() {
+				t.Error("Cell in pattern wasn't a locked clue", cell)
+			}
+		} else if cell.Number() != 0 {
+			t.Error("Cell not in pattern was filled", cell)
+		}
+	}
+
+	if GenerateGrid(&GenerationOptions{Pattern: pattern[:16]}) != nil {
+		t.Error("Generating with a pattern with too few cells returned a grid")
+	}
+}
+
+//A sparse pattern with 24 clues, far too few for random clues to have a
+//unique solution.
+const SPARSE_TEST_PATTERN = `
+..X.X...X
+XX.X.X...
+..XX....X
+.....X...
+......XXX
+.X.....X.
+...X.....
+.X..XX...
+.X...XX.X
+`
+
+func TestGenerateSparsePattern(t *testing.T) {
+	pattern, err := ParsePattern(SPARSE_TEST_PATTERN)
+
+	if err != nil {
+		t.Fatal("Couldn't parse pattern:", err)
+	}
+
+	if len(pattern) != 24 {
+		t.Fatal("Sparse pattern had wrong number of cells:", len(pattern))
+	}
+
+	grid := generateGridWithPattern(pattern)
+
+	if grid == nil {
+		t.Fatal("Couldn't generate grid with sparse pattern")
+	}
+
+	if grid.HasMultipleSolutions() {
+		t.Error("Generated grid with sparse pattern had multiple solutions")
+	}
+
+	inPattern := make(cellSet)
+	for _, ref := range pattern {
+		inPattern[ref] = true
+	}
+
+	for _, cell := range grid.Cells() {
+		if inPattern[cell.Reference()] != (cell.Number() != 0) {
+			t.Error("Cell didn't match sparse pattern", cell)
+		}
+	}
+}
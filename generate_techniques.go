@@ -0,0 +1,157 @@
+package sudoku
+
+import (
+	"errors"
+)
+
+//The number of grids to try before giving up on generating a grid that
+//requires the given techniques.
+const _MAX_TECHNIQUE_GENERATION_ATTEMPTS = 50
+
+//techniqueIndex returns the index of the technique in Techniques, or -1 if
+//it isn't in it.
+func techniqueIndex(technique SolveTechnique) int {
+	for i, item := range Techniques {
+		if item == technique {
+			return i
+		}
+	}
+	return -1
+}
+
+//allowedTechniques returns every technique in Techniques that is no harder
+//than the options' MaxTechnique (or, if that isn't set, the hardest of
+//RequiredTechniques).
+func (self *GenerationOptions) allowedTechniques() ([]SolveTechnique, error) {
+	if len(self.RequiredTechniques) == 0 {
+		return nil, errors.New("No required techniques provided")
+	}
+
+	maxIndex := -1
+
+	for _, technique := range self.RequiredTechniques {
+		index := techniqueIndex(technique)
+		if index < 0 {
+			return nil, errors.New("Required technique is not one of Techniques")
+		}
+		if index > maxIndex {
+			maxIndex = index
+		}
+	}
+
+	if self.MaxTechnique != nil {
+		index := techniqueIndex(self.MaxTechnique)
+		if index < 0 {
+			return nil, errors.New("MaxTechnique is not one of Techniques")
+		}
+		if index < maxIndex {
+			return nil, errors.New("A required technique is harder than MaxTechnique")
+		}
+		maxIndex = index
+	}
+
+	result := make([]SolveTechnique, maxIndex+1)
+	copy(result, Techniques[:maxIndex+1])
+	return result, nil
+}
+
+//restrictedSolveOptions returns HumanSolveOptions that only use the given
+//techniques and never guess.
+func restrictedSolveOptions(techniques []SolveTechnique) *HumanSolveOptions {
+	options := DefaultHumanSolveOptions()
+	//We only care whether it can be solved, not how a human would most
+	//likely solve it.
+	options.NumOptionsToCalculate = 1
+	options.TechniquesToUse = techniques
+	options.NoGuess = true
+	return options
+}
+
+//solvableWith returns true if HumanSolve can solve the grid with only the
+//given techniques.
+func solvableWith(grid Grid, techniques []SolveTechnique) bool {
+	return grid.HumanSolution(restrictedSolveOptions(techniques)) != nil
+}
+
+//withoutTechnique returns a copy of techniques without the given technique.
+func withoutTechnique(techniques []SolveTechnique, technique SolveTechnique) []SolveTechnique {
+	var result []SolveTechnique
+	for _, item := range techniques {
+		if item != technique {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+//GenerateGridWithTechniques generates a puzzle, useful for teaching, that
+//cannot be solved without each of options.RequiredTechniques, but that can
+//be solved without anything harder than options.MaxTechnique (where harder
+//means later in Techniques). Solvability is checked by running HumanSolve
+//with TechniquesToUse restricted. Alongside the grid it returns, for each
+//of RequiredTechniques, the first CompoundSolveStep in a solve of the grid
+//that uses that technique. Options besides Symmetry, SymmetryPercentage, and
+//MinFilledCells are ignored. Returns an error if the options are invalid or
+//no matching puzzle was found after many attempts; puzzles requiring rare
+//techniques can take a long time to generate.
+func GenerateGridWithTechniques(options *GenerationOptions) (MutableGrid, []*CompoundSolveStep, error) {
+
+	if options == nil {
+		return nil, nil, errors.New("No options provided")
+	}
+
+	allowed, err := options.allowedTechniques()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for attempt := 0; attempt < _MAX_TECHNIQUE_GENERATION_ATTEMPTS; attempt++ {
+
+		//Remove as many cells as we can while still being solvable with
+		//the allowed techniques, which makes it as likely as possible that
+		//the required techniques are needed.
+		grid := generateUnlockedGridWithCheck(options, func(grid MutableGrid) bool {
+			return solvableWith(grid, allowed)
+		})
+
+		requiresAll := true
+
+		for _, technique := range options.RequiredTechniques {
+			if solvableWith(grid, withoutTechnique(allowed, technique)) {
+				requiresAll = false
+				break
+			}
+		}
+
+		if !requiresAll {
+			continue
+		}
+
+		directions := grid.HumanSolution(restrictedSolveOptions(allowed))
+
+		if directions == nil {
+			continue
+		}
+
+		steps := make([]*CompoundSolveStep, len(options.RequiredTechniques))
+
+		for i, technique := range options.RequiredTechniques {
+		OuterLoop:
+			for _, compoundStep := range directions.CompoundSteps {
+				for _, step := range compoundStep.Steps() {
+					if step.Technique == technique {
+						steps[i] = compoundStep
+						break OuterLoop
+					}
+				}
+			}
+		}
+
+		grid.LockFilledCells()
+
+		return grid, steps, nil
+	}
+
+	return nil, nil, errors.New("Couldn't generate a grid that requires the techniques")
+}
@@ -0,0 +1,124 @@
+package sudoku
+
+import (
+	"testing"
+)
+
+func TestAllowedTechniques(t *testing.T) {
+	options := &GenerationOptions{}
+
+	if _, err := options.allowedTechniques(); err == nil {
+		t.Error("Didn't get an error with no required techniques")
+	}
+
+	options.RequiredTechniques = []SolveTechnique{techniquesByName["Necessary In Row"]}
+
+	allowed, err := options.allowedTechniques()
+
+	if err != nil {
+		t.Fatal("Got error for valid options:", err)
+	}
+
+	if len(allowed) != techniqueIndex(techniquesByName["Necessary In Row"])+1 {
+		t.Error("Got wrong number of allowed techniques:", len(allowed))
+	}
+
+	if allowed[len(allowed)-1] != techniquesByName["Necessary In Row"] {
+		t.Error("Hardest allowed technique wasn't the required one")
+	}
+
+	options.MaxTechnique = techniquesByName["XWing Row"]
+
+	allowed, err = options.allowedTechniques()
+
+	if err != nil {
+		t.Fatal("Got error for valid options:", err)
+	}
+
+	if allowed[len(allowed)-1] != techniquesByName["XWing Row"] {
+		t.Error("Hardest allowed technique wasn't MaxTechnique")
+	}
+
+	options.MaxTechnique = techniquesByName["Obvious In Block"]
+
+	if _, err := options.allowedTechniques(); err == nil {
+		t.Error("Didn't get an error with MaxTechnique easier than required technique")
+	}
+
+	options.MaxTechnique = GuessTechnique
+
+	if _, err := options.allowedTechniques(); err == nil {
+		t.Error("Didn't get an error with MaxTechnique not in Techniques")
+	}
+
+	options.MaxTechnique = nil
+	options.RequiredTechniques = []SolveTechnique{GuessTechnique}
+
+	if _, err := options.allowedTechniques(); err == nil {
+		t.Error("Didn't get an error with required technique not in Techniques")
+	}
+}
+
+func TestGenerateGridWithTechniques(t *testing.T) {
+	required := techniquesByName["Necessary In Row"]
+
+	options := &GenerationOptions{
+		Symmetry:           SYMMETRY_VERTICAL,
+		SymmetryPercentage: 0.7,
+		RequiredTechniques: []SolveTechnique{required},
+	}
+
+	grid, steps, err := GenerateGridWithTechniques(options)
+
+	if err != nil {
+		t.Fatal("Couldn't generate grid:", err)
+	}
+
+	if grid.HasMultipleSolutions() {
+		t.Error("Generated grid had multiple solutions")
+	}
+
+	allowed, _ := options.allowedTechniques()
+
+	if !solvableWith(grid, allowed) {
+		t.Error("Generated grid couldn't be solved with allowed techniques")
+	}
+
+	if solvableWith(grid, withoutTechnique(allowed, required)) {
+		t.Error("Generated grid could be solved without required technique")
+	}
+
+	if len(steps) != 1 || steps[0] == nil {
+		t.Fatal("Didn't get back the step using the technique:", steps)
+	}
+
+	foundTechnique := false
+	for _, step := range steps[0].Steps() {
+		if step.Technique == required {
+			foundTechnique = true
+		}
+	}
+
+	if !foundTechnique {
+		t.Error("Returned step didn't use required technique")
+	}
+
+	for _, cell := range grid.Cells() {
+		if cell.Number() != 0 && !cell.Locked() {
+			t.Error("Generated grid had unlocked filled cell", cell)
+			break
+		}
+	}
+
+	options.MaxTechnique = techniquesByName["Obvious In Block"]
+
+	grid, _, err = GenerateGridWithTechniques(options)
+
+	if err == nil || grid != nil {
+		t.Error("Didn't get error with invalid options")
+	}
+
+	if GenerateGrid(options) != nil {
+		t.Error("GenerateGrid with invalid required techniques returned a grid")
+	}
+}
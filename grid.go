@@ -119,6 +119,10 @@ type Grid interface {
 	//to the screen. Currently simply an alias for DataString.
 	String() string
 
+	//MarshalJSON encodes the grid, including its marks, locks, and excludes,
+	//as JSON. See MutableGrid.UnmarshalJSON to decode it.
+	MarshalJSON() ([]byte, error)
+
 	//Diagram returns a verbose visual representation of a grid, representing not
 	//just filled numbers but also what numbers in a cell are possible. If
 	//showMarks is true, instead of printing the possibles, it will print only the
@@ -149,6 +153,37 @@ type Grid interface {
 	//times until the difficultly number begins to converge to an average.
 	Difficulty() float64
 
+	//DifficultyDistribution does the same work as Difficulty, but instead of
+	//just the average it returns the difficulty of each of the runs, along
+	//with summary statistics and which techniques each run used. This is
+	//useful for seeing how much a puzzle's difficulty varies depending on how
+	//it's solved, and for flagging ratings that aren't reliable. Just as
+	//expensive as Difficulty.
+	DifficultyDistribution() *DifficultyDistribution
+
+	//DifficultyExplanation returns why the grid has the Difficulty it does:
+	//each signal extracted from solving it, its weight in the difficulty
+	//model, and how much it contributed, sorted with the biggest
+	//contributions first. Just as expensive as Difficulty (although it shares
+	//a cache with it).
+	DifficultyExplanation() DifficultyExplanation
+
+	//SudokuExplainerRating returns the rating of the puzzle on the
+	//community-standard Sudoku Explainer scale (1.0 to 11.0+). Unlike
+	//Difficulty, it doesn't try to model a human; it solves the puzzle by
+	//always applying the easiest step available and returns the rating of the
+	//hardest step it had to use. See SudokuExplainerRatings. Returns 0.0 if
+	//the puzzle doesn't have exactly one solution.
+	SudokuExplainerRating() float64
+
+	//HoDoKuRating returns the score and level of the puzzle as HoDoKu would
+	//rate it: the puzzle is solved by always applying the easiest step
+	//available, the score is the sum of the scores of each step, and the
+	//level is the higher of the level the score falls in and the level of the
+	//hardest technique used. See HoDoKuScores. Returns 0 and HODOKU_EASY if
+	//the puzzle doesn't have exactly one solution.
+	HoDoKuRating() (int, HoDoKuLevel)
+
 	//NumSolutions returns the total number of solutions found in the grid when it
 	//is solved forward from this point. A valid Sudoku puzzle has only one
 	//solution.
@@ -160,6 +195,38 @@ type Grid interface {
 	//HasMultipleSolutions returns true if the grid has more than one solution.
 	HasMultipleSolutions() bool
 
+	//IsMinimal returns true if the grid has exactly one solution and every
+	//filled cell is necessary for that to be true; that is, if removing any
+	//single filled cell would give the grid multiple solutions.
+	IsMinimal() bool
+
+	//RedundantClues returns the filled cells that could each be removed
+	//without the grid gaining more solutions. Note that removing one of them
+	//might make others necessary. Returns nil if the grid doesn't have
+	//exactly one solution.
+	RedundantClues() CellSlice
+
+	//Canonical returns the canonical form of the grid: of all of the grids
+	//that can be made from this one by relabeling digits, permuting bands or
+	//stacks, permuting rows or columns within their band or stack, or
+	//transposing, the one whose numbers sort first when read row by row.
+	//Every one of those grids is a valid sudoku if and only if this one is,
+	//and they all have the same canonical form, so it can be used to tell if
+	//two puzzles are really the same. Only numbers are considered; the
+	//returned grid has no marks, excludes, or locks.
+	Canonical() Grid
+
+	//Transform returns a copy of the grid transformed by transform, with
+	//every cell's number, marks, excludes, and lock moved and relabeled along
+	//with it. If this grid's difficulty has already been calculated, the
+	//copy reuses it.
+	Transform(transform *GridTransform) MutableGrid
+
+	//Equivalent returns true if other can be made from this grid by the
+	//transformations described in Canonical; that is, if they have the same
+	//canonical form.
+	Equivalent(other Grid) bool
+
 	//Solutions returns a slice of grids that represent possible solutions if you
 	//were to solve forward this grid. The current grid is not modified. If there
 	//are no solutions forward from this location it will return a slice with
@@ -200,6 +267,11 @@ type MutableGrid interface {
 	//opposed to returning a new one.
 	LoadSDK(data string)
 
+	//UnmarshalJSON replaces the contents of the grid with the grid encoded in
+	//data by MarshalJSON. Returns an error (leaving the grid unchanged) if
+	//data isn't a valid grid.
+	UnmarshalJSON(data []byte) error
+
 	//ResetExcludes calls ResetExcludes on all cells in the grid. See
 	//Cell.SetExcluded for more about excludes.
 	ResetExcludes()
@@ -291,6 +363,7 @@ type mutableGridImpl struct {
 	//asked for more.
 	cachedSolutionsRequestedLength int
 	cachedDifficulty               float64
+	cachedDifficultyDistribution   *DifficultyDistribution
 }
 
 //gridImpl is the default implementation of Grid.
@@ -1037,6 +1110,7 @@ func (self *mutableGridImpl) cellModified(cell MutableCell, oldNumber int) {
 	self.cachedSolutionsRequestedLength = -1
 	self.cachedSolutionsLock.Unlock()
 	self.cachedDifficulty = 0.0
+	self.cachedDifficultyDistribution = nil
 
 	if cell.Number() == 0 && oldNumber != 0 {
 		self.numFilledCellsCounter--
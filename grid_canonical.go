@@ -0,0 +1,234 @@
+package sudoku
+
+/*
+	This file finds the canonical form of a grid: of every grid that can be
+	made from it with transformations that keep a sudoku valid (relabeling
+	the digits, permuting bands and stacks, permuting rows and columns within
+	their band or stack, and transposing), the one whose numbers, read row by
+	row with 0 for unfilled cells, sort first. Two grids are equivalent
+	exactly when their canonical forms are the same.
+
+	There are far too many transformations to try each, so for every
+	transposition and column permutation we pick rows one at a time, only
+	following the rows that give the smallest possible next row (with digits
+	relabeled in the order they are first seen), and giving up as soon as we
+	can't beat the best form found so far.
+*/
+
+//Every permutation of a band or stack's three rows or columns.
+var _BLOCK_PERMUTATIONS = [][BLOCK_DIM]int{
+	{0, 1, 2},
+	{0, 2, 1},
+	{1, 0, 2},
+	{1, 2, 0},
+	{2, 0, 1},
+	{2, 1, 0},
+}
+
+//Every permutation of columns that keeps each stack's columns together.
+var _CANONICAL_COLUMN_PERMUTATIONS [][DIM]int
+
+func init() {
+	for _, stacks := range _BLOCK_PERMUTATIONS {
+		for _, first := range _BLOCK_PERMUTATIONS {
+			for _, second := range _BLOCK_PERMUTATIONS {
+				for _, third := range _BLOCK_PERMUTATIONS {
+					var permutation [DIM]int
+					for i, within := range [][BLOCK_DIM]int{first, second, third} {
+						for j := 0; j < BLOCK_DIM; j++ {
+							permutation[i*BLOCK_DIM+j] = stacks[i]*BLOCK_DIM + within[j]
+						}
+					}
+					_CANONICAL_COLUMN_PERMUTATIONS = append(_CANONICAL_COLUMN_PERMUTATIONS, permutation)
+				}
+			}
+		}
+	}
+}
+
+type canonicalizer struct {
+	//The grid's numbers after transposing and permuting columns.
+	numbers [DIM][DIM]int
+	best    [DIM][DIM]int
+	hasBest bool
+}
+
+//relabeledRow returns row with each number relabeled according to labels,
+//assigning the next unused label to each number seen for the first time.
+//labels and nextLabel are updated in place.
+func relabeledRow(row [DIM]int, labels *[DIM + 1]int, nextLabel *int) [DIM]int {
+	var result [DIM]int
+	for i, number := range row {
+		if number == 0 {
+			continue
+		}
+		if labels[number] == 0 {
+			labels[number] = *nextLabel
+			*nextLabel++
+		}
+		result[i] = labels[number]
+	}
+	return result
+}
+
+//compareRows returns -1, 0, or 1 if one sorts before, the same as, or after
+//two.
+func compareRows(one [DIM]int, two [DIM]int) int {
+	for i := range one {
+		if one[i] < two[i] {
+			return -1
+		}
+		if one[i] > two[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+//couldBeBest returns true if the first rows rows of result sort before, or
+//are the same as, those of the best form found so far.
+func (self *canonicalizer) couldBeBest(result *[DIM][DIM]int, rows int) bool {
+	if !self.hasBest {
+		return true
+	}
+	for i := 0; i < rows; i++ {
+		comparison := compareRows(result[i], self.best[i])
+		if comparison != 0 {
+			return comparison < 0
+		}
+	}
+	return true
+}
+
+//search fills in row index of result, having already used the rows in used.
+//band is the band the previous row came from.
+func (self *canonicalizer) search(index int, band int, used [DIM]bool, result [DIM][DIM]int, labels [DIM + 1]int, nextLabel int) {
+
+	if index == DIM {
+		self.best = result
+		self.hasBest = true
+		return
+	}
+
+	type option struct {
+		row       int
+		labels    [DIM + 1]int
+		nextLabel int
+	}
+
+	var options []option
+	var smallest [DIM]int
+
+	for row := 0; row < DIM; row++ {
+		if used[row] {
+			continue
+		}
+		//The first row of a band can come from any unused band; the rest
+		//must come from the same band as the first.
+		if index%BLOCK_DIM != 0 && row/BLOCK_DIM != band {
+			continue
+		}
+		//Picking a row that is the same as one we already picked from the
+		//same band would give exactly the same results.
+		duplicate := false
+		for _, item := range options {
+			if item.row/BLOCK_DIM == row/BLOCK_DIM && self.numbers[item.row] == self.numbers[row] {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		candidateLabels := labels
+		candidateNextLabel := nextLabel
+		relabeled := relabeledRow(self.numbers[row], &candidateLabels, &candidateNextLabel)
+		if len(options) > 0 {
+			comparison := compareRows(relabeled, smallest)
+			if comparison > 0 {
+				continue
+			}
+			if comparison < 0 {
+				options = options[:0]
+			}
+		}
+		smallest = relabeled
+		options = append(options, option{row, candidateLabels, candidateNextLabel})
+	}
+
+	result[index] = smallest
+
+	for _, item := range options {
+		//Checked each time since an earlier option may have improved best.
+		if !self.couldBeBest(&result, index+1) {
+			return
+		}
+		nextUsed := used
+		nextUsed[item.row] = true
+		self.search(index+1, item.row/BLOCK_DIM, nextUsed, result, item.labels, item.nextLabel)
+	}
+}
+
+//canonicalNumbers returns the numbers of the canonical form of grid.
+func canonicalNumbers(grid Grid) [DIM][DIM]int {
+	searcher := &canonicalizer{}
+
+	//Many permutations give the same numbers when columns are the same (for
+	//example, when they are empty), so there's no need to search them again.
+	searched := make(map[[DIM][DIM]int]bool)
+
+	for _, transpose := range []bool{false, true} {
+		var base [DIM][DIM]int
+		for _, cell := range grid.Cells() {
+			if transpose {
+				base[cell.Col()][cell.Row()] = cell.Number()
+			} else {
+				base[cell.Row()][cell.Col()] = cell.Number()
+			}
+		}
+		for _, permutation := range _CANONICAL_COLUMN_PERMUTATIONS {
+			for r := 0; r < DIM; r++ {
+				for c := 0; c < DIM; c++ {
+					searcher.numbers[r][c] = base[r][permutation[c]]
+				}
+			}
+			if searched[searcher.numbers] {
+				continue
+			}
+			searched[searcher.numbers] = true
+			var labels [DIM + 1]int
+			searcher.search(0, 0, [DIM]bool{}, [DIM][DIM]int{}, labels, 1)
+		}
+	}
+
+	return searcher.best
+}
+
+func (self *gridImpl) Canonical() Grid {
+	numbers := canonicalNumbers(self)
+	result := NewGrid()
+	for r := 0; r < DIM; r++ {
+		for c := 0; c < DIM; c++ {
+			result.MutableCell(r, c).SetNumber(numbers[r][c])
+		}
+	}
+	return result.Copy()
+}
+
+func (self *mutableGridImpl) Canonical() Grid {
+	return self.Copy().Canonical()
+}
+
+func (self *gridImpl) Equivalent(other Grid) bool {
+	if other == nil {
+		return false
+	}
+	if self.numFilledCells() != other.numFilledCells() {
+		return false
+	}
+	return canonicalNumbers(self) == canonicalNumbers(other)
+}
+
+func (self *mutableGridImpl) Equivalent(other Grid) bool {
+	return self.Copy().Equivalent(other)
+}
@@ -0,0 +1,98 @@
+package sudoku
+
+import (
+	"testing"
+)
+
+//shuffledGrid returns a copy of the numbers of grid with its bands, rows,
+//stacks, and columns permuted, its digits relabeled, and optionally
+//transposed.
+func shuffledGrid(grid Grid, transpose bool) MutableGrid {
+	rows := [DIM]int{7, 6, 8, 1, 0, 2, 5, 3, 4}
+	cols := [DIM]int{3, 5, 4, 8, 7, 6, 0, 1, 2}
+	labels := [DIM + 1]int{0, 5, 9, 1, 3, 2, 8, 4, 7, 6}
+
+	result := NewGrid()
+
+	for r := 0; r < DIM; r++ {
+		for c := 0; c < DIM; c++ {
+			number := labels[grid.Cell(rows[r], cols[c]).Number()]
+			if transpose {
+				result.MutableCell(c, r).SetNumber(number)
+			} else {
+				result.MutableCell(r, c).SetNumber(number)
+			}
+		}
+	}
+
+	return result
+}
+
+func TestCanonical(t *testing.T) {
+	grid := LoadSDK(TEST_GRID)
+
+	canonical := grid.Canonical()
+
+	if canonical.numFilledCells() != grid.numFilledCells() {
+		t.Error("Canonical form had a different number of filled cells")
+	}
+
+	if canonical.NumSolutions() != 1 {
+		t.Error("Canonical form of a valid puzzle wasn't valid")
+	}
+
+	if canonical.Canonical().DataString() != canonical.DataString() {
+		t.Error("Canonical form of canonical form wasn't the same")
+	}
+
+	for _, transpose := range []bool{false, true} {
+		shuffled := shuffledGrid(grid, transpose)
+
+		if shuffled.DataString() == grid.DataString() {
+			t.Fatal("Shuffling didn't change the grid")
+		}
+
+		if shuffled.Canonical().DataString() != canonical.DataString() {
+			t.Error("Shuffled grid had a different canonical form. Transposed:", transpose)
+		}
+
+		if !grid.Equivalent(shuffled) || !shuffled.Equivalent(grid) {
+			t.Error("Shuffled grid wasn't equivalent. Transposed:", transpose)
+		}
+	}
+
+	if canonical.Cell(0, 0).Number() != 0 {
+		t.Error("Canonical form didn't start with an unfilled cell:", canonical)
+	}
+}
+
+func TestNotEquivalent(t *testing.T) {
+	grid := LoadSDK(TEST_GRID)
+
+	//Swapping two rows in different bands doesn't preserve validity.
+	other := NewGrid()
+	for _, cell := range grid.Cells() {
+		row := cell.Row()
+		if row == 0 {
+			row = DIM - 1
+		} else if row == DIM-1 {
+			row = 0
+		}
+		other.MutableCell(row, cell.Col()).SetNumber(cell.Number())
+	}
+
+	if grid.Equivalent(other) {
+		t.Error("Grid with rows swapped across bands was equivalent")
+	}
+
+	if grid.Equivalent(nil) {
+		t.Error("Grid was equivalent to nil")
+	}
+
+	fewer := grid.MutableCopy()
+	fewer.MutableCell(0, 0).SetNumber(0)
+
+	if grid.Equivalent(fewer) {
+		t.Error("Grid with a clue removed was equivalent")
+	}
+}
@@ -0,0 +1,43 @@
+package sudoku
+
+func (self *gridImpl) IsMinimal() bool {
+	if !self.HasSolution() || self.HasMultipleSolutions() {
+		return false
+	}
+	return len(self.RedundantClues()) == 0
+}
+
+func (self *mutableGridImpl) IsMinimal() bool {
+	return self.Copy().IsMinimal()
+}
+
+func (self *gridImpl) RedundantClues() CellSlice {
+	if !self.HasSolution() || self.HasMultipleSolutions() {
+		return nil
+	}
+
+	var result CellSlice
+
+	for _, cell := range self.Cells() {
+		if cell.Number() == 0 {
+			continue
+		}
+		//Removing a clue can only add solutions, never remove them.
+		modification := newCellModification(cell.Reference())
+		modification.Number = 0
+		withoutClue := self.CopyWithModifications(GridModification{modification})
+		if !withoutClue.HasMultipleSolutions() {
+			result = append(result, cell)
+		}
+	}
+
+	return result
+}
+
+func (self *mutableGridImpl) RedundantClues() CellSlice {
+	var result CellSlice
+	for _, cell := range self.Copy().RedundantClues() {
+		result = append(result, cell.InGrid(self))
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package sudoku
+
+import (
+	"testing"
+)
+
+func TestRedundantClues(t *testing.T) {
+	grid := NewGrid()
+	grid.LoadSDK(TEST_GRID)
+
+	if grid.IsMinimal() {
+		t.Error("Test grid reported as minimal")
+	}
+
+	redundant := grid.RedundantClues()
+
+	if len(redundant) == 0 {
+		t.Fatal("Test grid had no redundant clues")
+	}
+
+	for _, cell := range redundant {
+		if cell.Number() == 0 {
+			t.Error("Got unfilled cell as a redundant clue", cell)
+		}
+		if cell.MutableInGrid(grid) == nil {
+			t.Error("Redundant clue wasn't in the grid", cell)
+		}
+		withoutClue := grid.MutableCopy()
+		cell.MutableInGrid(withoutClue).SetNumber(0)
+		if withoutClue.HasMultipleSolutions() {
+			t.Error("Removing redundant clue gave multiple solutions", cell)
+		}
+	}
+
+	//Remove redundant clues one by one until there are none left.
+	for len(redundant) > 0 {
+		redundant[0].MutableInGrid(grid).SetNumber(0)
+		redundant = grid.RedundantClues()
+	}
+
+	if !grid.IsMinimal() {
+		t.Error("Grid with no redundant clues wasn't minimal")
+	}
+
+	if grid.Copy().RedundantClues() != nil {
+		t.Error("Immutable copy of minimal grid had redundant clues")
+	}
+
+	empty := NewGrid()
+
+	if empty.IsMinimal() {
+		t.Error("Empty grid reported as minimal")
+	}
+
+	if empty.RedundantClues() != nil {
+		t.Error("Grid with multiple solutions had redundant clues")
+	}
+}
+
+func TestGenerateMinimal(t *testing.T) {
+	options := GenerationOptions{
+		Symmetry:           SYMMETRY_VERTICAL,
+		SymmetryPercentage: 1.0,
+		Minimal:            true,
+	}
+
+	grid := GenerateGrid(&options)
+
+	if !grid.IsMinimal() {
+		t.Error("Generated grid with Minimal wasn't minimal. Redundant clues:", grid.RedundantClues())
+	}
+
+	options.MinFilledCells = 40
+
+	grid = GenerateGrid(&options)
+
+	if !grid.IsMinimal() {
+		t.Error("Generated grid with Minimal and MinFilledCells wasn't minimal. Redundant clues:", grid.RedundantClues())
+	}
+
+	//Targeting an easy difficulty adds clues, which have to be balanced by
+	//removing the ones they make redundant.
+	options.MinFilledCells = 0
+	options.MaxDifficulty = 0.69
+	options.difficulty = fakeGenerationDifficulty
+
+	grid = GenerateGrid(&options)
+
+	if !grid.IsMinimal() {
+		t.Error("Generated grid with Minimal and a target difficulty wasn't minimal. Redundant clues:", grid.RedundantClues())
+	}
+}
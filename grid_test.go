@@ -981,6 +981,170 @@ func TestGenerationOptions(t *testing.T) {
 	if options.MinFilledCells != 0 {
 		t.Error("Wrong MinFilledCells")
 	}
+
+	if options.MinDifficulty != 0.0 || options.MaxDifficulty != 1.0 {
+		t.Error("Wrong difficulty range")
+	}
+
+	if options.targetsDifficulty() {
+		t.Error("Default options targeted a difficulty")
+	}
+
+	options.MaxDifficulty = 0.0
+
+	if options.targetsDifficulty() {
+		t.Error("Options with a MaxDifficulty of 0.0 targeted a difficulty")
+	}
+
+	options.MinDifficulty = 0.3
+	options.MaxDifficulty = 0.5
+
+	if !options.targetsDifficulty() {
+		t.Error("Options with a difficulty range didn't target a difficulty")
+	}
+
+	for _, test := range []struct {
+		difficulty float64
+		expected   float64
+	}{
+		{0.1, 0.2},
+		{0.3, 0.0},
+		{0.4, 0.0},
+		{0.7, 0.2},
+	} {
+		if math.Abs(options.difficultyDistance(test.difficulty)-test.expected) > 0.00001 {
+			t.Error("Wrong difficulty distance for", test.difficulty, "Got", options.difficultyDistance(test.difficulty), "expected", test.expected)
+		}
+	}
+}
+
+//fakeGenerationDifficulty is a stand in for the expensive difficulty
+//calculation in generation: the proportion of cells that are unfilled.
+func fakeGenerationDifficulty(grid MutableGrid, accurate bool) float64 {
+	return float64(DIM*DIM-grid.numFilledCells()) / float64(DIM*DIM)
+}
+
+func TestGenerateDifficulty(t *testing.T) {
+	options := GenerationOptions{
+		Symmetry:           SYMMETRY_VERTICAL,
+		SymmetryPercentage: 0.7,
+		MinDifficulty:      0.4,
+		MaxDifficulty:      0.5,
+		difficulty:         fakeGenerationDifficulty,
+	}
+
+	grid := GenerateGrid(&options)
+
+	difficulty := fakeGenerationDifficulty(grid, true)
+
+	if difficulty < options.MinDifficulty || difficulty > options.MaxDifficulty {
+		t.Error("Generated grid had difficulty outside of range:", difficulty)
+	}
+
+	if grid.HasMultipleSolutions() {
+		t.Error("Generated grid had multiple solutions")
+	}
+
+	for _, cell := range grid.Cells() {
+		if cell.Number() != 0 && !cell.Locked() {
+			t.Error("Generated grid had unlocked filled cell", cell)
+			break
+		}
+	}
+}
+
+func TestGenerateDifficultyRealModel(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("Skipping TestGenerateDifficultyRealModel in short test mode,")
+	}
+
+	//A wide range, so this should converge quickly even though every
+	//difficulty calculation is expensive.
+	options := GenerationOptions{
+		Symmetry:           SYMMETRY_VERTICAL,
+		SymmetryPercentage: 0.7,
+		MinDifficulty:      0.05,
+		MaxDifficulty:      0.95,
+	}
+
+	estimates := 0
+
+	options.difficulty = func(grid MutableGrid, accurate bool) float64 {
+		if !accurate {
+			estimates++
+		}
+		return generationDifficulty(grid, accurate)
+	}
+
+	grid := GenerateGrid(&options)
+
+	if grid.HasMultipleSolutions() {
+		t.Error("Generated grid had multiple solutions")
+	}
+
+	//Difficulty was cached when the search confirmed it.
+	if difficulty := grid.Difficulty(); difficulty < options.MinDifficulty || difficulty > options.MaxDifficulty {
+		t.Error("Generated grid had difficulty outside of range:", difficulty)
+	}
+
+	if estimates > _DIFFICULTY_GENERATION_CANDIDATES+1 {
+		t.Error("Generating in a wide difficulty range took too many estimates:", estimates)
+	}
+}
+
+func TestDifficultyNeighbor(t *testing.T) {
+	options := &GenerationOptions{
+		Symmetry:           SYMMETRY_NONE,
+		SymmetryPercentage: 0.0,
+	}
+
+	grid := generateUnlockedGrid(options)
+	solution := grid.MutableCopy()
+	solution.Fill()
+
+	easier := difficultyNeighbor(grid, solution, false, options)
+
+	if easier == nil {
+		t.Fatal("Couldn't make grid easier")
+	}
+
+	if easier.numFilledCells() != grid.numFilledCells()+1 {
+		t.Error("Easier grid didn't have one more filled cell. Got", easier.numFilledCells(), "expected", grid.numFilledCells()+1)
+	}
+
+	for _, cell := range easier.Cells() {
+		if cell.Number() != 0 && cell.Number() != cell.InGrid(solution).Number() {
+			t.Error("Easier grid added a clue that doesn't match the solution", cell)
+		}
+	}
+
+	harder := difficultyNeighbor(easier, solution, true, options)
+
+	if harder == nil {
+		t.Fatal("Couldn't make grid harder")
+	}
+
+	if harder.numFilledCells() != easier.numFilledCells()-1 {
+		t.Error("Harder grid didn't have one fewer filled cell. Got", harder.numFilledCells(), "expected", easier.numFilledCells()-1)
+	}
+
+	if harder.HasMultipleSolutions() {
+		t.Error("Harder grid had multiple solutions")
+	}
+
+	//The generated grid is already minimal, so making it harder requires a
+	//swap.
+	swapped := difficultyNeighbor(grid, solution, true, options)
+
+	if swapped != nil {
+		if swapped.HasMultipleSolutions() {
+			t.Error("Swapped grid had multiple solutions")
+		}
+		if swapped.DataString() == grid.DataString() {
+			t.Error("Swapped grid didn't change")
+		}
+	}
 }
 
 //This is an extremely expensive test desgined to help ferret out #134.
@@ -1024,6 +1188,33 @@ func TestGenerateMinFilledCells(t *testing.T) {
 	}
 }
 
+func TestSymmetricalGenerateNewTypes(t *testing.T) {
+	for _, symmetry := range []SymmetryType{SYMMETRY_DIAGONAL, SYMMETRY_ANTI_DIAGONAL, SYMMETRY_ROTATIONAL_90} {
+		options := GenerationOptions{
+			Symmetry:           symmetry,
+			SymmetryPercentage: 1.0,
+		}
+
+		grid := GenerateGrid(&options)
+
+		if grid == nil {
+			t.Fatal("Did not get a generated grid back for symmetry", symmetry)
+		}
+
+		if grid.HasMultipleSolutions() {
+			t.Error("Generated grid for symmetry", symmetry, "had multiple solutions")
+		}
+
+		for _, cell := range grid.MutableCells() {
+			for _, other := range mutableSymmetricalOrbit(cell, symmetry) {
+				if (cell.Number() == 0) != (other.Number() == 0) {
+					t.Error("Cell", cell.Reference(), "and its partner", other.Reference(), "didn't match for symmetry", symmetry)
+				}
+			}
+		}
+	}
+}
+
 func TestSymmetricalGenerate(t *testing.T) {
 	options := GenerationOptions{
 		Symmetry:           SYMMETRY_VERTICAL,
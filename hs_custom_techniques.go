@@ -0,0 +1,196 @@
+package sudoku
+
+import (
+	"errors"
+	"math"
+)
+
+/*
+	This file is where SolveTechniques defined outside of this package are
+	plugged in.
+
+	SolveTechnique has private methods so that only this package can implement
+	it. Packages that want to add their own techniques instead implement
+	CustomSolveTechnique, and hand it to RegisterTechnique, which wraps it in
+	a SolveTechnique and adds it to Techniques so that HumanSolve, hints, and
+	the difficulty signals all use it.
+*/
+
+//FindCoordinator is passed to CustomSolveTechnique.Find and is how the
+//technique reports the steps it finds. It is the public equivalent of the
+//contract the built-in techniques use.
+type FindCoordinator interface {
+	//ShouldExitEarly will return true when it's OK for the technique to exit
+	//even if not all of the SolveSteps have been returned. Techniques should
+	//check this periodically while they search.
+	ShouldExitEarly() bool
+
+	//FoundResult should be called whenever a result has been found. Only pass
+	//steps that are valid and useful; steps that wouldn't do useful work are
+	//dropped. The step's Technique will be set to the registered
+	//SolveTechnique. The return result is equivalent to ShouldExitEarly(); if
+	//it is true, the technique should finish up.
+	FoundResult(step *SolveStep) bool
+}
+
+//CustomSolveTechnique is the interface that techniques defined outside of
+//this package implement to participate in HumanSolve. See RegisterTechnique.
+type CustomSolveTechnique interface {
+	//Name returns the human-readable shortname of the technique. It must be
+	//unique across all techniques.
+	Name() string
+	//Description returns a human-readable phrase that describes the logical
+	//reasoning applied in the particular step; why it is valid.
+	Description(step *SolveStep) string
+	//IsFill returns true if the techinque's action when applied to a grid is
+	//to fill a number (as opposed to culling possbilities).
+	IsFill() bool
+	//HumanLikelihood is how likely a user would be to pick this technique when
+	//compared with other possible steps; lower is more likely. step may be
+	//nil, in which case the result should be the "normal" value of the
+	//technique, which is used to sort it among the other Techniques.
+	HumanLikelihood(step *SolveStep) float64
+	//Find searches the grid for steps and passes every one it finds to
+	//coordinator.FoundResult, stopping early if the coordinator asks it to.
+	//The grid must not be modified.
+	Find(grid Grid, coordinator FindCoordinator)
+}
+
+//CustomSolveTechniqueVariants is an optional interface that a
+//CustomSolveTechnique can implement if the steps it produces come in more
+//than one TechniqueVariant. Techniques that don't implement it have a single
+//variant that is the same as their name.
+type CustomSolveTechniqueVariants interface {
+	//Variants returns all of the variant names that Variant could ever
+	//return.
+	Variants() []string
+	//Variant returns the variant name of the given step.
+	Variant(step *SolveStep) string
+}
+
+//customSolveTechnique wraps a CustomSolveTechnique so that it can be used as
+//a SolveTechnique.
+type customSolveTechnique struct {
+	*basicSolveTechnique
+	custom CustomSolveTechnique
+}
+
+//publicFindCoordinator adapts a findCoordinator to a FindCoordinator for use
+//by a CustomSolveTechnique.
+type publicFindCoordinator struct {
+	coordinator findCoordinator
+	technique   SolveTechnique
+	grid        Grid
+}
+
+//RegisterTechnique adds the given technique to Techniques (and
+//AllTechniques, AllTechniqueVariants), where it will be used by HumanSolve
+//and friends, and returns the SolveTechnique that represents it. Returns an
+//error if the technique is invalid or a technique or variant with the same
+//name already exists. Techniques should be registered before any solving
+//happens (for example, in an init function); HumanSolveOptions that were
+//created before the technique was registered will not use it. Registering
+//new techniques changes the set of difficulty signals, so the difficulty
+//model may need to be retrained.
+func RegisterTechnique(technique CustomSolveTechnique) (SolveTechnique, error) {
+
+	if technique == nil {
+		return nil, errors.New("No technique provided")
+	}
+
+	name := technique.Name()
+
+	if name == "" {
+		return nil, errors.New("Technique has no name")
+	}
+
+	if _, ok := techniquesByName[name]; ok {
+		return nil, errors.New("A technique named " + name + " already exists")
+	}
+
+	likelihood := technique.HumanLikelihood(nil)
+
+	if math.IsNaN(likelihood) || math.IsInf(likelihood, 0) || likelihood <= 0.0 {
+		return nil, errors.New("Technique " + name + " has an invalid HumanLikelihood")
+	}
+
+	result := &customSolveTechnique{
+		&basicSolveTechnique{
+			name,
+			technique.IsFill(),
+			_GROUP_NONE,
+			1,
+		},
+		technique,
+	}
+
+	existingVariants := make(map[string]bool)
+	for _, variant := range AllTechniqueVariants {
+		existingVariants[variant] = true
+	}
+
+	for _, variant := range result.Variants() {
+		if existingVariants[variant] {
+			return nil, errors.New("A technique variant named " + variant + " already exists")
+		}
+	}
+
+	//Don't modify Techniques in place, in case someone is holding on to it.
+	techniques := make([]SolveTechnique, 0, len(Techniques)+1)
+	techniques = append(techniques, Techniques...)
+	Techniques = append(techniques, result)
+
+	indexTechniques()
+
+	return result, nil
+}
+
+func (self *customSolveTechnique) humanLikelihood(step *SolveStep) float64 {
+	return self.custom.HumanLikelihood(step)
+}
+
+func (self *customSolveTechnique) Description(step *SolveStep) string {
+	return self.custom.Description(step)
+}
+
+func (self *customSolveTechnique) Variants() []string {
+	if variants, ok := self.custom.(CustomSolveTechniqueVariants); ok {
+		return variants.Variants()
+	}
+	return self.basicSolveTechnique.Variants()
+}
+
+func (self *customSolveTechnique) variant(step *SolveStep) string {
+	if variants, ok := self.custom.(CustomSolveTechniqueVariants); ok {
+		return variants.Variant(step)
+	}
+	return self.basicSolveTechnique.variant(step)
+}
+
+func (self *customSolveTechnique) Candidates(grid Grid, maxResults int) []*SolveStep {
+	return self.candidatesHelper(self, grid, maxResults)
+}
+
+func (self *customSolveTechnique) find(grid Grid, coordinator findCoordinator) {
+	self.custom.Find(grid, &publicFindCoordinator{
+		coordinator: coordinator,
+		technique:   self,
+		grid:        grid,
+	})
+}
+
+func (self *publicFindCoordinator) ShouldExitEarly() bool {
+	return self.coordinator.shouldExitEarly()
+}
+
+func (self *publicFindCoordinator) FoundResult(step *SolveStep) bool {
+	if step == nil {
+		return self.coordinator.shouldExitEarly()
+	}
+	step.Technique = self.technique
+	//A step that doesn't do anything would make HumanSolve loop forever.
+	if !step.IsUseful(self.grid) {
+		return self.coordinator.shouldExitEarly()
+	}
+	return self.coordinator.foundResult(step)
+}
@@ -0,0 +1,135 @@
+package sudoku
+
+import (
+	"testing"
+)
+
+//lastPossibilityTechnique is a re-implementation of Only Legal Number that
+//only uses the public API, like a technique from another package would.
+type lastPossibilityTechnique struct {
+	name string
+}
+
+func (self *lastPossibilityTechnique) Name() string {
+	return self.name
+}
+
+func (self *lastPossibilityTechnique) Description(step *SolveStep) string {
+	return step.TargetNums.Description() + " is the only number left in " + step.TargetCells.Description()
+}
+
+func (self *lastPossibilityTechnique) IsFill() bool {
+	return true
+}
+
+func (self *lastPossibilityTechnique) HumanLikelihood(step *SolveStep) float64 {
+	//Lower than everything else, so HumanSolve will always prefer it.
+	return 0.001
+}
+
+func (self *lastPossibilityTechnique) Find(grid Grid, coordinator FindCoordinator) {
+	for _, cell := range grid.Cells() {
+		if coordinator.ShouldExitEarly() {
+			return
+		}
+		possibilities := cell.Possibilities()
+		if cell.Number() != 0 || len(possibilities) != 1 {
+			continue
+		}
+		step := &SolveStep{
+			TargetCells: CellRefSlice{cell.Reference()},
+			TargetNums:  possibilities,
+		}
+		if coordinator.FoundResult(step) {
+			return
+		}
+	}
+}
+
+//restoreTechniques returns a func that will reset all of the package-level
+//technique state to what it was when restoreTechniques was called.
+func restoreTechniques() func() {
+	techniques := Techniques
+	return func() {
+		Techniques = techniques
+		indexTechniques()
+	}
+}
+
+func TestRegisterTechnique(t *testing.T) {
+
+	defer restoreTechniques()()
+
+	numTechniques := len(Techniques)
+	numVariants := len(AllTechniqueVariants)
+
+	technique, err := RegisterTechnique(&lastPossibilityTechnique{"Last Possibility"})
+
+	if err != nil {
+		t.Fatal("Couldn't register technique:", err)
+	}
+
+	if len(Techniques) != numTechniques+1 {
+		t.Error("Registering didn't add to Techniques")
+	}
+
+	if Techniques[0] != technique {
+		t.Error("Registered technique wasn't sorted to the front of Techniques")
+	}
+
+	if len(AllTechniqueVariants) != numVariants+1 || AllTechniqueVariants[0] != "Last Possibility" {
+		t.Error("Registered technique's variant not in AllTechniqueVariants:", AllTechniqueVariants)
+	}
+
+	if techniquesByName["Last Possibility"] != technique {
+		t.Error("Registered technique not available by name")
+	}
+
+	if _, err := RegisterTechnique(&lastPossibilityTechnique{"Last Possibility"}); err == nil {
+		t.Error("Didn't get an error registering a technique with the same name twice")
+	}
+
+	if _, err := RegisterTechnique(&lastPossibilityTechnique{"Only Legal Number"}); err == nil {
+		t.Error("Didn't get an error registering a technique with a built-in's name")
+	}
+
+	grid := LoadSDK(TEST_GRID)
+
+	steps := technique.Candidates(grid, 0)
+
+	if len(steps) == 0 {
+		t.Fatal("Registered technique didn't find any steps")
+	}
+
+	for _, step := range steps {
+		if step.Technique != technique {
+			t.Error("Step had the wrong technique:", step.Technique)
+		}
+		if step.TechniqueVariant() != "Last Possibility" {
+			t.Error("Step had the wrong variant:", step.TechniqueVariant())
+		}
+	}
+
+	directions := grid.HumanSolution(nil)
+
+	if directions == nil {
+		t.Fatal("Couldn't human solve with a registered technique")
+	}
+
+	usedTechnique := false
+
+	for _, step := range directions.Steps() {
+		if step.Technique == technique {
+			usedTechnique = true
+			break
+		}
+	}
+
+	if !usedTechnique {
+		t.Error("HumanSolve never used the registered technique")
+	}
+
+	if _, ok := directions.Signals()["Last Possibility Count"]; !ok {
+		t.Error("Difficulty signals didn't include the registered technique")
+	}
+}
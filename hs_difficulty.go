@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -52,6 +53,8 @@ func init() {
 //return.
 func LoadDifficultyModel(model map[string]float64) {
 	difficultySignalWeights = model
+	//Any metadata from a model loaded from a file no longer applies.
+	difficultyModelMetadata = nil
 	//Reset the stored model hash value so the next call to
 	//DifficultyModelHash will recacluate it.
 	difficultyModelHashValue = ""
@@ -274,6 +277,99 @@ func (self DifficultySignals) difficulty() float64 {
 	return accum
 }
 
+//DifficultyContribution is how much a single signal contributed to a
+//difficulty.
+type DifficultyContribution struct {
+	//The name of the signal, or "Constant" for the model's constant term.
+	Signal string
+	//The value of the signal. Always 1.0 for the constant term.
+	Value float64
+	//The weight the current difficulty model gives the signal.
+	Weight float64
+	//Value * Weight. Positive contributions make the puzzle harder, negative
+	//ones make it easier.
+	Contribution float64
+}
+
+//DifficultyExplanation is a list of how each signal contributed to a
+//difficulty, sorted so the contributions with the biggest impact (in either
+//direction) come first. The sum of every Contribution is the difficulty
+//before it is clamped to between 0.0 and 1.0.
+type DifficultyExplanation []DifficultyContribution
+
+type difficultyExplanationByImpact DifficultyExplanation
+
+func (d difficultyExplanationByImpact) Len() int {
+	return len(d)
+}
+
+func (d difficultyExplanationByImpact) Swap(i, j int) {
+	d[i], d[j] = d[j], d[i]
+}
+
+func (d difficultyExplanationByImpact) Less(i, j int) bool {
+	iImpact := math.Abs(d[i].Contribution)
+	jImpact := math.Abs(d[j].Contribution)
+	if iImpact == jImpact {
+		//Keep a stable order for equal impacts
+		return d[i].Signal < d[j].Signal
+	}
+	return iImpact > jImpact
+}
+
+//Explanation returns how much each of the signals (and the constant term of
+//the model) contributes to the difficulty that these signals produce with
+//the currently loaded difficulty model.
+func (self DifficultySignals) Explanation() DifficultyExplanation {
+	var result DifficultyExplanation
+
+	if constant, ok := difficultySignalWeights["Constant"]; ok {
+		result = append(result, DifficultyContribution{
+			Signal:       "Constant",
+			Value:        1.0,
+			Weight:       constant,
+			Contribution: constant,
+		})
+	}
+
+	for signal, val := range self {
+		weight := difficultySignalWeights[signal]
+		result = append(result, DifficultyContribution{
+			Signal:       signal,
+			Value:        val,
+			Weight:       weight,
+			Contribution: val * weight,
+		})
+	}
+
+	sort.Sort(difficultyExplanationByImpact(result))
+
+	return result
+}
+
+//Total returns the sum of all of the contributions, which is the difficulty
+//before it is clamped to between 0.0 and 1.0.
+func (self DifficultyExplanation) Total() float64 {
+	result := 0.0
+	for _, item := range self {
+		result += item.Contribution
+	}
+	return result
+}
+
+//Description returns a line for each contribution that has any impact,
+//suitable for printing.
+func (self DifficultyExplanation) Description() []string {
+	var result []string
+	for _, item := range self {
+		if item.Contribution == 0.0 {
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s: %f (%f * %f)", item.Signal, item.Contribution, item.Value, item.Weight))
+	}
+	return result
+}
+
 //Rest of file is different Signals
 
 //TODO: now that SolveDirections includes gridSnapshot, think if there are any
@@ -0,0 +1,96 @@
+package sudoku
+
+import (
+	"sort"
+)
+
+/*
+	This file estimates the difficulty of each individual step of a solve.
+	Difficulty() rates the puzzle as a whole, which can hide a solve that is
+	easy except for one brutal step. The difficulty model is linear, so many
+	of its signals are sums over every step of the solve: a count of each
+	technique used, the number of steps, and the number of cells filled.
+	A step's difficulty is its share of those sums, weighted by the
+	currently loaded model, so the curve is on the same scale as
+	Grid.Difficulty and changes when a new model is loaded.
+*/
+
+//How much harder than the typical step in a solve a step must be to be
+//considered a bottleneck. On the scale of Grid.Difficulty, this is a tenth
+//of the distance from the easiest puzzle to the hardest.
+const _BOTTLENECK_DIFFICULTY_THRESHOLD = 0.1
+
+//stepDifficulty returns how much step adds to the difficulty of a solve it
+//is part of, via the signals that count steps, with the currently loaded
+//difficulty model.
+func stepDifficulty(step *SolveStep) float64 {
+	result := difficultySignalWeights["Number of Steps"]
+	result += difficultySignalWeights[step.TechniqueVariant()+" Count"]
+	if step.Technique != nil && step.Technique.IsFill() {
+		result += difficultySignalWeights["Number Unfilled Cells"]
+	}
+	return result
+}
+
+//Difficulty returns an estimate of how hard this step is, on the same scale
+//as Grid.Difficulty: how much its steps add to the difficulty of any solve
+//it's part of, according to the weights the currently loaded difficulty
+//model gives each technique. Signals that depend on the solve as a whole,
+//like the percentage of steps that use each technique and the constant
+//term, aren't included. Because the model can give easy techniques
+//negative weights, the result can be below 0.0; it is not clamped.
+func (c *CompoundSolveStep) Difficulty() float64 {
+	result := 0.0
+
+	for _, step := range c.Steps() {
+		if step == nil {
+			continue
+		}
+		result += stepDifficulty(step)
+	}
+
+	return result
+}
+
+//DifficultyCurve returns the Difficulty of each of the CompoundSteps, in
+//order, showing how the difficulty of the solve changes as it goes.
+func (self SolveDirections) DifficultyCurve() []float64 {
+	result := make([]float64, len(self.CompoundSteps))
+	for i, step := range self.CompoundSteps {
+		result[i] = step.Difficulty()
+	}
+	return result
+}
+
+//Bottlenecks returns the indexes into CompoundSteps of the steps that are
+//much harder than the typical (median) step in the solve: steps whose
+//Difficulty is at least 0.1 more than the median's. A puzzle that is
+//easy except for one or two bottlenecks is often frustrating to play.
+func (self SolveDirections) Bottlenecks() []int {
+	curve := self.DifficultyCurve()
+
+	if len(curve) < 2 {
+		return nil
+	}
+
+	sorted := make([]float64, len(curve))
+	copy(sorted, curve)
+	sort.Float64s(sorted)
+
+	var median float64
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	} else {
+		median = sorted[len(sorted)/2]
+	}
+
+	var result []int
+
+	for i, difficulty := range curve {
+		if difficulty-median >= _BOTTLENECK_DIFFICULTY_THRESHOLD {
+			result = append(result, i)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,159 @@
+package sudoku
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func fillOnlyCompoundStep(techniqueName string) *CompoundSolveStep {
+	return &CompoundSolveStep{
+		FillStep: &SolveStep{
+			Technique: techniquesByName[techniqueName],
+		},
+	}
+}
+
+//testStepDifficultyModel is a difficulty model with simple weights for the
+//signals that count steps.
+var testStepDifficultyModel = map[string]float64{
+	"Constant":                 0.1,
+	"Number of Steps":          0.01,
+	"Number Unfilled Cells":    -0.005,
+	"Obvious In Block Count":   0.0,
+	"Necessary In Block Count": 0.01,
+	"Naked Pair Block Count":   0.05,
+	"Naked Pair Row Count":     0.06,
+	"Guess Count":              0.2,
+}
+
+func TestCompoundSolveStepDifficulty(t *testing.T) {
+	currentModel := difficultySignalWeights
+	defer LoadDifficultyModel(currentModel)
+
+	LoadDifficultyModel(testStepDifficultyModel)
+
+	easy := fillOnlyCompoundStep("Obvious In Block")
+
+	if math.Abs(easy.Difficulty()-0.005) > 0.00001 {
+		t.Error("Easy step had wrong difficulty:", easy.Difficulty())
+	}
+
+	guess := fillOnlyCompoundStep("Guess")
+
+	if math.Abs(guess.Difficulty()-0.205) > 0.00001 {
+		t.Error("Guess step had wrong difficulty:", guess.Difficulty())
+	}
+
+	withPrecursor := &CompoundSolveStep{
+		PrecursorSteps: []*SolveStep{
+			{Technique: techniquesByName["Naked Pair Block"]},
+		},
+		FillStep: &SolveStep{
+			Technique: techniquesByName["Obvious In Block"],
+		},
+	}
+
+	expected := 0.06 + 0.005
+
+	if math.Abs(withPrecursor.Difficulty()-expected) > 0.00001 {
+		t.Error("Step with precursor had wrong difficulty. Got", withPrecursor.Difficulty(), "expected", expected)
+	}
+
+	withPrecursor.PrecursorSteps = append(withPrecursor.PrecursorSteps, &SolveStep{Technique: techniquesByName["Naked Pair Row"]})
+
+	if math.Abs(withPrecursor.Difficulty()-(expected+0.07)) > 0.00001 {
+		t.Error("Step with more precursor steps had wrong difficulty. Got", withPrecursor.Difficulty(), "expected", expected+0.07)
+	}
+
+	//The difficulty follows the loaded model.
+	LoadDifficultyModel(map[string]float64{"Constant": 0.0, "Guess Count": 0.5})
+
+	if guess.Difficulty() != 0.5 {
+		t.Error("Step difficulty didn't use the loaded model. Got", guess.Difficulty())
+	}
+}
+
+func TestDifficultyCurve(t *testing.T) {
+	currentModel := difficultySignalWeights
+	defer LoadDifficultyModel(currentModel)
+
+	LoadDifficultyModel(testStepDifficultyModel)
+
+	directions := SolveDirections{
+		nil,
+		[]*CompoundSolveStep{
+			fillOnlyCompoundStep("Obvious In Block"),
+			fillOnlyCompoundStep("Necessary In Block"),
+			fillOnlyCompoundStep("Guess"),
+			fillOnlyCompoundStep("Obvious In Row"),
+			fillOnlyCompoundStep("Obvious In Col"),
+		},
+	}
+
+	curve := directions.DifficultyCurve()
+
+	if len(curve) != len(directions.CompoundSteps) {
+		t.Fatal("Curve had wrong length. Got", len(curve))
+	}
+
+	for i, difficulty := range curve {
+		if difficulty != directions.CompoundSteps[i].Difficulty() {
+			t.Error("Curve at", i, "didn't match step's difficulty")
+		}
+	}
+
+	bottlenecks := directions.Bottlenecks()
+
+	if !reflect.DeepEqual(bottlenecks, []int{2}) {
+		t.Error("Got wrong bottlenecks. Got", bottlenecks, "expected [2]")
+	}
+
+	uniform := SolveDirections{
+		nil,
+		[]*CompoundSolveStep{
+			fillOnlyCompoundStep("Guess"),
+			fillOnlyCompoundStep("Guess"),
+			fillOnlyCompoundStep("Guess"),
+		},
+	}
+
+	if bottlenecks := uniform.Bottlenecks(); len(bottlenecks) != 0 {
+		t.Error("Uniformly hard solve had bottlenecks:", bottlenecks)
+	}
+
+	grid, err := MutableLoadSDKFromFile(puzzlePath("harddifficulty.sdk"))
+
+	if err != nil {
+		t.Fatal("Couldn't load grid:", err)
+	}
+
+	//With the real model, the curve adds up to the contributions of the
+	//signals that count steps to the puzzle's difficulty.
+	LoadDifficultyModel(currentModel)
+
+	solution := grid.HumanSolution(nil)
+
+	curve = solution.DifficultyCurve()
+
+	if len(curve) != len(solution.CompoundSteps) {
+		t.Error("Real solve had wrong length difficulty curve")
+	}
+
+	total := 0.0
+	for _, difficulty := range curve {
+		total += difficulty
+	}
+
+	expected := 0.0
+	for _, contribution := range solution.Signals().Explanation() {
+		if contribution.Signal == "Number of Steps" || contribution.Signal == "Number Unfilled Cells" || strings.HasSuffix(contribution.Signal, " Count") {
+			expected += contribution.Contribution
+		}
+	}
+
+	if math.Abs(total-expected) > 0.00001 {
+		t.Error("Difficulty curve didn't add up to the step signals' contributions. Got", total, "expected", expected)
+	}
+}
@@ -0,0 +1,161 @@
+package sudoku
+
+import (
+	"math"
+	"sort"
+)
+
+//If the standard deviation of the difficulty samples is larger than this,
+//the difficulty is considered unstable.
+const _UNSTABLE_DIFFICULTY_STD_DEV = 0.05
+
+//The suffix of the signals that count how often each TechniqueVariant was
+//used.
+const _TECHNIQUE_COUNT_SIGNAL_SUFFIX = " Count"
+
+//DifficultyDistribution is the full set of results from calculating a grid's
+//difficulty. Calculating difficulty involves repeatedly human solving the
+//puzzle until the average difficulty converges; each run (which itself
+//averages together a number of solves) is a sample. Get one from
+//Grid.DifficultyDistribution.
+type DifficultyDistribution struct {
+	//Samples is the difficulty of each run, in the order they were run.
+	Samples []float64
+	//TechniqueCounts is, for each run, how many times on average each
+	//TechniqueVariant was used in the solves in that run. It is parallel to
+	//Samples.
+	TechniqueCounts []map[string]float64
+	//Mean is the average of Samples. This is the value that Difficulty
+	//returns.
+	Mean float64
+	//StdDev is the standard deviation of Samples.
+	StdDev float64
+	//Min is the lowest value in Samples.
+	Min float64
+	//Max is the highest value in Samples.
+	Max float64
+	//Converged is true if the average stopped changing before the maximum
+	//number of runs was reached.
+	Converged bool
+	//Signals is the average of the signals from every run. Because the
+	//difficulty model is linear, this is what Explanation is based on.
+	Signals DifficultySignals
+}
+
+//addSample records the result of one run.
+func (self *DifficultyDistribution) addSample(difficulty float64, signals DifficultySignals) {
+	self.Samples = append(self.Samples, difficulty)
+
+	counts := make(map[string]float64)
+
+	for _, variant := range AllTechniqueVariants {
+		if value, ok := signals[variant+_TECHNIQUE_COUNT_SIGNAL_SUFFIX]; ok {
+			counts[variant] = value
+		}
+	}
+
+	self.TechniqueCounts = append(self.TechniqueCounts, counts)
+
+	if self.Signals == nil {
+		self.Signals = DifficultySignals{}
+	}
+
+	//Keep a running sum for now; finish will turn it into an average.
+	self.Signals.sum(signals)
+}
+
+//finish calculates all of the summary statistics once all samples have been
+//added.
+func (self *DifficultyDistribution) finish() {
+	if len(self.Samples) == 0 {
+		return
+	}
+
+	self.Min = math.Inf(1)
+	self.Max = math.Inf(-1)
+
+	accum := 0.0
+
+	for _, sample := range self.Samples {
+		accum += sample
+		self.Min = math.Min(self.Min, sample)
+		self.Max = math.Max(self.Max, sample)
+	}
+
+	self.Mean = accum / float64(len(self.Samples))
+
+	squaredDifferences := 0.0
+
+	for _, sample := range self.Samples {
+		squaredDifferences += (sample - self.Mean) * (sample - self.Mean)
+	}
+
+	self.StdDev = math.Sqrt(squaredDifferences / float64(len(self.Samples)))
+
+	for signal := range self.Signals {
+		self.Signals[signal] /= float64(len(self.Samples))
+	}
+}
+
+//Explanation returns how much each signal contributed to the difficulty,
+//based on the average signals across all of the runs.
+func (self *DifficultyDistribution) Explanation() DifficultyExplanation {
+	return self.Signals.Explanation()
+}
+
+//Percentile returns the difficulty below which the given proportion (0.0 to
+//1.0) of the samples fall, interpolating between samples if necessary. For
+//example, Percentile(0.9) is the difficulty that 90% of the runs came in at
+//or below.
+func (self *DifficultyDistribution) Percentile(proportion float64) float64 {
+	if len(self.Samples) == 0 {
+		return 0.0
+	}
+
+	sorted := make([]float64, len(self.Samples))
+	copy(sorted, self.Samples)
+	sort.Float64s(sorted)
+
+	if proportion <= 0.0 {
+		return sorted[0]
+	}
+	if proportion >= 1.0 {
+		return sorted[len(sorted)-1]
+	}
+
+	position := proportion * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+
+	fraction := position - float64(lower)
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*fraction
+}
+
+//Unstable returns true if the difficulty varied so much from run to run (or
+//never converged) that the Mean shouldn't be relied upon.
+func (self *DifficultyDistribution) Unstable() bool {
+	return !self.Converged || self.StdDev > _UNSTABLE_DIFFICULTY_STD_DEV
+}
+
+//AverageTechniqueCounts returns how many times on average each
+//TechniqueVariant was used, across all of the runs.
+func (self *DifficultyDistribution) AverageTechniqueCounts() map[string]float64 {
+	result := make(map[string]float64)
+
+	if len(self.TechniqueCounts) == 0 {
+		return result
+	}
+
+	for _, counts := range self.TechniqueCounts {
+		for technique, count := range counts {
+			result[technique] += count
+		}
+	}
+
+	for technique := range result {
+		result[technique] /= float64(len(self.TechniqueCounts))
+	}
+
+	return result
+}
@@ -0,0 +1,126 @@
+package sudoku
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDifficultyDistributionStats(t *testing.T) {
+	distribution := &DifficultyDistribution{}
+
+	for _, sample := range []float64{0.5, 0.2, 0.4, 0.3, 0.6} {
+		distribution.addSample(sample, DifficultySignals{
+			"Only Legal Number Count": sample * 10,
+			"Number of Steps":         3.0,
+		})
+	}
+
+	distribution.Converged = true
+
+	distribution.finish()
+
+	if math.Abs(distribution.Mean-0.4) > 0.0000001 {
+		t.Error("Wrong mean:", distribution.Mean)
+	}
+
+	if math.Abs(distribution.StdDev-math.Sqrt(0.02)) > 0.0000001 {
+		t.Error("Wrong std dev:", distribution.StdDev)
+	}
+
+	if distribution.Min != 0.2 || distribution.Max != 0.6 {
+		t.Error("Wrong min or max:", distribution.Min, distribution.Max)
+	}
+
+	tests := []struct {
+		proportion float64
+		expected   float64
+	}{
+		{0.0, 0.2},
+		{0.5, 0.4},
+		{1.0, 0.6},
+		{0.875, 0.55},
+		{-1.0, 0.2},
+		{2.0, 0.6},
+	}
+
+	for _, test := range tests {
+		if result := distribution.Percentile(test.proportion); math.Abs(result-test.expected) > 0.0000001 {
+			t.Error("Percentile", test.proportion, "got", result, "expected", test.expected)
+		}
+	}
+
+	if !distribution.Unstable() {
+		t.Error("Distribution with a large std dev wasn't unstable")
+	}
+
+	if len(distribution.TechniqueCounts) != 5 {
+		t.Fatal("Wrong number of technique counts:", len(distribution.TechniqueCounts))
+	}
+
+	if distribution.TechniqueCounts[0]["Only Legal Number"] != 5.0 {
+		t.Error("Wrong technique count for first run:", distribution.TechniqueCounts[0])
+	}
+
+	if _, ok := distribution.TechniqueCounts[0]["Number of Steps"]; ok {
+		t.Error("Technique counts included a signal that isn't a technique")
+	}
+
+	if math.Abs(distribution.AverageTechniqueCounts()["Only Legal Number"]-4.0) > 0.0000001 {
+		t.Error("Wrong average technique count:", distribution.AverageTechniqueCounts())
+	}
+
+	stableDistribution := &DifficultyDistribution{
+		Converged: true,
+	}
+	stableDistribution.addSample(0.5, nil)
+	stableDistribution.addSample(0.51, nil)
+	stableDistribution.finish()
+
+	if stableDistribution.Unstable() {
+		t.Error("Distribution with a small std dev was unstable")
+	}
+
+	stableDistribution.Converged = false
+
+	if !stableDistribution.Unstable() {
+		t.Error("Distribution that didn't converge wasn't unstable")
+	}
+
+	if (&DifficultyDistribution{}).Percentile(0.5) != 0.0 {
+		t.Error("Empty distribution had a non-zero percentile")
+	}
+}
+
+func TestCalculateDifficultyDistribution(t *testing.T) {
+	grid := LoadSDK(TEST_GRID)
+
+	//We use the cheaper one for testing so it completes faster.
+	distribution := calculateDifficultyDistribution(grid, false)
+
+	if len(distribution.Samples) != 1 {
+		t.Fatal("Wrong number of samples:", distribution.Samples)
+	}
+
+	if distribution.Mean != distribution.Samples[0] || distribution.Min != distribution.Mean || distribution.Max != distribution.Mean {
+		t.Error("Summary stats didn't match the single sample:", distribution)
+	}
+
+	if distribution.Mean < 0.0 || distribution.Mean > 1.0 {
+		t.Error("Difficulty out of bounds:", distribution.Mean)
+	}
+
+	counts := distribution.TechniqueCounts[0]
+
+	if len(counts) != len(AllTechniqueVariants) {
+		t.Error("Technique counts didn't include every variant:", counts)
+	}
+
+	total := 0.0
+	for _, count := range counts {
+		total += count
+	}
+
+	if total == 0.0 {
+		t.Error("Technique counts didn't count any steps")
+	}
+}
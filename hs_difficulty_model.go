@@ -0,0 +1,182 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"time"
+)
+
+//DIFFICULTY_MODEL_VERSION is the version of the on-disk difficulty model
+//format that SaveToFile writes. LoadDifficultyModelFromFile will refuse to
+//load models with a newer version.
+const DIFFICULTY_MODEL_VERSION = 1
+
+//The name of the term in a difficulty model that isn't tied to any signal.
+const _DIFFICULTY_CONSTANT_SIGNAL = "Constant"
+
+//DifficultyModel is a difficulty model along with metadata about how it was
+//trained, in the form that is saved to and loaded from disk as JSON.
+type DifficultyModel struct {
+	//Version is the version of the file format. See
+	//DIFFICULTY_MODEL_VERSION.
+	Version int
+	//R2 is how well the model fit the data it was trained on.
+	R2 float64
+	//TrainingDate is when the model was trained.
+	TrainingDate time.Time
+	//NumOptionsToCalculate is the HumanSolveOptions.NumOptionsToCalculate
+	//that was used to generate the signals the model was trained on.
+	//Difficulties will be off if the solve options in use differ.
+	NumOptionsToCalculate int
+	//Signals is the list of every signal the model was trained with.
+	Signals []string
+	//Weights is the weight for each signal, plus the "Constant" term. This
+	//is what is passed to LoadDifficultyModel.
+	Weights map[string]float64
+}
+
+//difficultyModelMetadata is the model most recently loaded with
+//LoadDifficultyModelFromFile, or nil if the current model was loaded some
+//other way.
+var difficultyModelMetadata *DifficultyModel
+
+//knownDifficultySignals returns the name of every signal that the signal
+//generators currently produce.
+func knownDifficultySignals() map[string]bool {
+	result := map[string]bool{
+		_DIFFICULTY_CONSTANT_SIGNAL: true,
+	}
+	//Signal generators always return the same keys, so the keys from an
+	//empty set of directions are all of them.
+	for signal := range (SolveDirections{}).Signals() {
+		result[signal] = true
+	}
+	return result
+}
+
+//Validate returns an error if the model is not a version that can be
+//loaded, has no weights, or references signals that don't exist.
+func (self *DifficultyModel) Validate() error {
+	if self.Version < 1 || self.Version > DIFFICULTY_MODEL_VERSION {
+		return errors.New("Unsupported difficulty model version")
+	}
+	if len(self.Weights) == 0 {
+		return errors.New("Difficulty model has no weights")
+	}
+	if _, ok := self.Weights[_DIFFICULTY_CONSTANT_SIGNAL]; !ok {
+		return errors.New("Difficulty model has no Constant weight")
+	}
+
+	known := knownDifficultySignals()
+
+	for _, signal := range self.Signals {
+		if !known[signal] {
+			return errors.New("Difficulty model references unknown signal: " + signal)
+		}
+	}
+
+	for signal := range self.Weights {
+		if !known[signal] {
+			return errors.New("Difficulty model has weight for unknown signal: " + signal)
+		}
+	}
+
+	return nil
+}
+
+//SaveToFile writes the model as JSON to the given path. If the model's
+//Version or Signals aren't set, they will be filled in first.
+func (self *DifficultyModel) SaveToFile(path string) error {
+	if self.Version == 0 {
+		self.Version = DIFFICULTY_MODEL_VERSION
+	}
+	if self.Signals == nil {
+		for signal := range self.Weights {
+			if signal == _DIFFICULTY_CONSTANT_SIGNAL {
+				continue
+			}
+			self.Signals = append(self.Signals, signal)
+		}
+		sort.Strings(self.Signals)
+	}
+
+	if err := self.Validate(); err != nil {
+		return err
+	}
+
+	jsonBlob, err := json.MarshalIndent(self, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, jsonBlob, 0644)
+}
+
+//LoadDifficultyModelFromFile loads a difficulty model saved with
+//DifficultyModel.SaveToFile and uses it to score puzzles' difficulties, as
+//LoadDifficultyModel does. It returns an error, and leaves the current model
+//in place, if the file can't be read or the model fails Validate. If the
+//model was trained with a different NumOptionsToCalculate than the default
+//HumanSolveOptions use, it will still be loaded but a warning is logged.
+func LoadDifficultyModelFromFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	model := &DifficultyModel{}
+
+	if err := json.Unmarshal(data, model); err != nil {
+		return err
+	}
+
+	if err := model.Validate(); err != nil {
+		return err
+	}
+
+	defaultNumOptions := DefaultHumanSolveOptions().NumOptionsToCalculate
+
+	if model.NumOptionsToCalculate != defaultNumOptions {
+		log.Println("Difficulty model was trained with NumOptionsToCalculate of", model.NumOptionsToCalculate, "but solves use", defaultNumOptions, "so difficulties may be off.")
+	}
+
+	LoadDifficultyModel(model.Weights)
+	difficultyModelMetadata = model
+
+	return nil
+}
+
+//CurrentDifficultyModel returns the difficulty model currently in use. If
+//it was loaded with LoadDifficultyModelFromFile it will include the
+//metadata from the file; otherwise only Version, Signals, and Weights are
+//set. Modifying the result does not modify the model in use.
+func CurrentDifficultyModel() *DifficultyModel {
+	result := &DifficultyModel{
+		Version: DIFFICULTY_MODEL_VERSION,
+	}
+
+	if difficultyModelMetadata != nil {
+		*result = *difficultyModelMetadata
+	}
+
+	result.Weights = make(map[string]float64, len(difficultySignalWeights))
+	var signals []string
+	for signal, weight := range difficultySignalWeights {
+		result.Weights[signal] = weight
+		if signal != _DIFFICULTY_CONSTANT_SIGNAL {
+			signals = append(signals, signal)
+		}
+	}
+
+	if difficultyModelMetadata != nil && difficultyModelMetadata.Signals != nil {
+		result.Signals = append([]string(nil), difficultyModelMetadata.Signals...)
+	} else {
+		sort.Strings(signals)
+		result.Signals = signals
+	}
+
+	return result
+}
@@ -0,0 +1,132 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDifficultyModelRoundTrip(t *testing.T) {
+	currentModel := difficultySignalWeights
+	defer LoadDifficultyModel(currentModel)
+
+	model := CurrentDifficultyModel()
+
+	if err := model.Validate(); err != nil {
+		t.Fatal("Built in model didn't validate:", err)
+	}
+
+	model.R2 = 0.6556
+	model.TrainingDate = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+	model.NumOptionsToCalculate = DefaultHumanSolveOptions().NumOptionsToCalculate
+
+	path := filepath.Join(t.TempDir(), "model.json")
+
+	if err := model.SaveToFile(path); err != nil {
+		t.Fatal("Couldn't save model:", err)
+	}
+
+	//Load a different model so we can tell the file was loaded.
+	LoadDifficultyModel(map[string]float64{"Constant": 0.5})
+
+	if CurrentDifficultyModel().R2 != 0.0 {
+		t.Error("LoadDifficultyModel didn't clear the metadata")
+	}
+
+	if err := LoadDifficultyModelFromFile(path); err != nil {
+		t.Fatal("Couldn't load model:", err)
+	}
+
+	if !reflect.DeepEqual(difficultySignalWeights, currentModel) {
+		t.Error("Loading model from file didn't restore the weights")
+	}
+
+	loaded := CurrentDifficultyModel()
+
+	if !reflect.DeepEqual(loaded, model) {
+		t.Error("Loaded model didn't match saved model. Got", loaded, "expected", model)
+	}
+}
+
+func TestDifficultyModelValidation(t *testing.T) {
+	currentModel := difficultySignalWeights
+	defer LoadDifficultyModel(currentModel)
+
+	tests := []struct {
+		description string
+		model       *DifficultyModel
+	}{
+		{
+			"future version",
+			&DifficultyModel{
+				Version: DIFFICULTY_MODEL_VERSION + 1,
+				Weights: map[string]float64{"Constant": 0.5},
+			},
+		},
+		{
+			"no weights",
+			&DifficultyModel{
+				Version: DIFFICULTY_MODEL_VERSION,
+			},
+		},
+		{
+			"no constant",
+			&DifficultyModel{
+				Version: DIFFICULTY_MODEL_VERSION,
+				Weights: map[string]float64{"Number of Steps": 0.5},
+			},
+		},
+		{
+			"unknown weight",
+			&DifficultyModel{
+				Version: DIFFICULTY_MODEL_VERSION,
+				Weights: map[string]float64{"Constant": 0.5, "Not A Signal": 0.2},
+			},
+		},
+		{
+			"unknown signal",
+			&DifficultyModel{
+				Version: DIFFICULTY_MODEL_VERSION,
+				Signals: []string{"Not A Signal"},
+				Weights: map[string]float64{"Constant": 0.5},
+			},
+		},
+	}
+
+	dir := t.TempDir()
+
+	for _, test := range tests {
+		if err := test.model.Validate(); err == nil {
+			t.Error("Model with", test.description, "validated")
+		}
+
+		path := filepath.Join(dir, "model.json")
+
+		if err := test.model.SaveToFile(path); err == nil {
+			t.Error("Model with", test.description, "could be saved")
+		}
+
+		//Write it directly, since SaveToFile won't.
+		data, err := json.Marshal(test.model)
+		if err != nil {
+			t.Fatal("Couldn't marshal model:", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal("Couldn't write test file:", err)
+		}
+
+		if err := LoadDifficultyModelFromFile(path); err == nil {
+			t.Error("Loading model with", test.description, "from file succeeded")
+		}
+		if !reflect.DeepEqual(difficultySignalWeights, currentModel) {
+			t.Error("Failed load of model with", test.description, "changed the model")
+		}
+	}
+
+	if err := LoadDifficultyModelFromFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Loading a missing file succeeded")
+	}
+}
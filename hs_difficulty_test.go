@@ -207,6 +207,82 @@ func TestSumDifficultySignals(t *testing.T) {
 	}
 }
 
+func TestDifficultyExplanation(t *testing.T) {
+	currentModel := difficultySignalWeights
+	defer LoadDifficultyModel(currentModel)
+
+	LoadDifficultyModel(map[string]float64{
+		"Constant": 0.1,
+		"a":        0.2,
+		"b":        -0.3,
+		"c":        0.05,
+	})
+
+	signals := DifficultySignals{
+		"a": 1.0,
+		"b": 2.0,
+		"c": 1.0,
+		"d": 4.0,
+	}
+
+	explanation := signals.Explanation()
+
+	golden := DifficultyExplanation{
+		{"b", 2.0, -0.3, -0.6},
+		{"a", 1.0, 0.2, 0.2},
+		{"Constant", 1.0, 0.1, 0.1},
+		{"c", 1.0, 0.05, 0.05},
+		{"d", 4.0, 0.0, 0.0},
+	}
+
+	if len(explanation) != len(golden) {
+		t.Fatal("Explanation had wrong number of items. Got", explanation, "expected", golden)
+	}
+
+	for i, item := range explanation {
+		goldenItem := golden[i]
+		if item.Signal != goldenItem.Signal || item.Value != goldenItem.Value || item.Weight != goldenItem.Weight || math.Abs(item.Contribution-goldenItem.Contribution) > 0.00001 {
+			t.Error("Explanation item", i, "was wrong. Got", item, "expected", goldenItem)
+		}
+	}
+
+	if math.Abs(explanation.Total()-(-0.25)) > 0.00001 {
+		t.Error("Explanation total was wrong. Got", explanation.Total(), "expected -0.25")
+	}
+
+	description := explanation.Description()
+
+	if len(description) != 4 {
+		t.Error("Description should skip items with no contribution. Got", description)
+	}
+
+	if !strings.HasPrefix(description[0], "b: ") {
+		t.Error("Description should start with the biggest impact. Got", description[0])
+	}
+}
+
+func TestGridDifficultyExplanation(t *testing.T) {
+	grid, err := MutableLoadSDKFromFile(puzzlePath("harddifficulty.sdk"))
+
+	if err != nil {
+		t.Fatal("Couldn't load grid", err)
+	}
+
+	explanation := grid.DifficultyExplanation()
+
+	if len(explanation) == 0 {
+		t.Fatal("Got empty explanation for grid")
+	}
+
+	difficulty := grid.Difficulty()
+
+	total := math.Max(0.0, math.Min(1.0, explanation.Total()))
+
+	if math.Abs(total-difficulty) > 0.00001 {
+		t.Error("Explanation didn't add up to the difficulty. Got", total, "expected", difficulty)
+	}
+}
+
 func TestSolveDirectionsSignals(t *testing.T) {
 	result := sampleSolveDirections.Signals()
 	golden := DifficultySignals{}
@@ -0,0 +1,160 @@
+package sudoku
+
+/*
+	This file implements an alternate way of rating puzzles that mirrors
+	HoDoKu's scoring. HoDoKu solves the puzzle with the easiest step at each
+	point, sums up a score for every step it took, and then classifies the
+	puzzle into one of five levels based on both the score and the hardest
+	technique that was required.
+*/
+
+//HoDoKuLevel is one of the difficulty levels that HoDoKu classifies puzzles
+//into.
+type HoDoKuLevel int
+
+const (
+	HODOKU_EASY HoDoKuLevel = iota
+	HODOKU_MEDIUM
+	HODOKU_HARD
+	HODOKU_UNFAIR
+	HODOKU_EXTREME
+)
+
+//HoDoKuScore is the score that HoDoKu gives each use of a technique, and the
+//minimum level that any puzzle that requires the technique will be.
+type HoDoKuScore struct {
+	Score int
+	Level HoDoKuLevel
+}
+
+//HoDoKuScores is the HoDoKu score for each TechniqueVariant, based on the
+//score HoDoKu gives its closest equivalent technique. Each also has the
+//lowest level a puzzle that needs the technique can be, so a puzzle with a
+//low score can still be rated hard if it needs one hard step.
+var HoDoKuScores = map[string]HoDoKuScore{
+	"Obvious In Block":         {4, HODOKU_EASY},
+	"Obvious In Row":           {4, HODOKU_EASY},
+	"Obvious In Col":           {4, HODOKU_EASY},
+	"Only Legal Number":        {4, HODOKU_EASY},
+	"Necessary In Block":       {14, HODOKU_EASY},
+	"Necessary In Row":         {14, HODOKU_EASY},
+	"Necessary In Col":         {14, HODOKU_EASY},
+	"Pointing Pair Row":        {50, HODOKU_MEDIUM},
+	"Pointing Pair Col":        {50, HODOKU_MEDIUM},
+	"Block Block Interactions": {50, HODOKU_MEDIUM},
+	"Naked Pair Block":         {60, HODOKU_MEDIUM},
+	"Naked Pair Row":           {60, HODOKU_MEDIUM},
+	"Naked Pair Col":           {60, HODOKU_MEDIUM},
+	"Hidden Pair Block":        {70, HODOKU_MEDIUM},
+	"Hidden Pair Row":          {70, HODOKU_MEDIUM},
+	"Hidden Pair Col":          {70, HODOKU_MEDIUM},
+	"Naked Triple Block":       {80, HODOKU_MEDIUM},
+	"Naked Triple Row":         {80, HODOKU_MEDIUM},
+	"Naked Triple Col":         {80, HODOKU_MEDIUM},
+	"Hidden Triple Block":      {100, HODOKU_MEDIUM},
+	"Hidden Triple Row":        {100, HODOKU_MEDIUM},
+	"Hidden Triple Col":        {100, HODOKU_MEDIUM},
+	"Naked Quad Block":         {120, HODOKU_HARD},
+	"Naked Quad Row":           {120, HODOKU_HARD},
+	"Naked Quad Col":           {120, HODOKU_HARD},
+	"XWing Row":                {140, HODOKU_HARD},
+	"XWing Col":                {140, HODOKU_HARD},
+	"Swordfish Row":            {150, HODOKU_HARD},
+	"Swordfish Col":            {150, HODOKU_HARD},
+	"Hidden Quad Block":        {150, HODOKU_HARD},
+	"Hidden Quad Row":          {150, HODOKU_HARD},
+	"Hidden Quad Col":          {150, HODOKU_HARD},
+	"XYWing":                   {160, HODOKU_HARD},
+	"XYWing (Same Block)":      {160, HODOKU_HARD},
+	"Forcing Chain (1 steps)":  {500, HODOKU_EXTREME},
+	"Forcing Chain (2 steps)":  {500, HODOKU_EXTREME},
+	"Forcing Chain (3 steps)":  {500, HODOKU_EXTREME},
+	"Forcing Chain (4 steps)":  {500, HODOKU_EXTREME},
+	"Forcing Chain (5 steps)":  {500, HODOKU_EXTREME},
+	"Forcing Chain (6 steps)":  {500, HODOKU_EXTREME},
+	"Pattern Overlay":          {10000, HODOKU_EXTREME},
+	"Pattern Overlay Fill":     {10000, HODOKU_EXTREME},
+	"Guess":                    {10000, HODOKU_EXTREME},
+}
+
+//hodokuMaxScores is the maximum score a puzzle can have and still be in
+//each level, regardless of which techniques it uses.
+var hodokuMaxScores = []struct {
+	level    HoDoKuLevel
+	maxScore int
+}{
+	{HODOKU_EASY, 800},
+	{HODOKU_MEDIUM, 1000},
+	{HODOKU_HARD, 1600},
+	{HODOKU_UNFAIR, 1800},
+}
+
+//String returns the name HoDoKu uses for the level.
+func (self HoDoKuLevel) String() string {
+	switch self {
+	case HODOKU_EASY:
+		return "Easy"
+	case HODOKU_MEDIUM:
+		return "Medium"
+	case HODOKU_HARD:
+		return "Hard"
+	case HODOKU_UNFAIR:
+		return "Unfair"
+	case HODOKU_EXTREME:
+		return "Extreme"
+	}
+	return "Unknown"
+}
+
+//hodokuScore returns the HoDoKu score of the given variant as a float64 (so
+//it can be used with easiestSolution), and whether it has one.
+func hodokuScore(variant string) (float64, bool) {
+	score, ok := HoDoKuScores[variant]
+	return float64(score.Score), ok
+}
+
+//HoDoKuRating returns the HoDoKu score of the SolveDirections--the sum of
+//the score of each step--along with the HoDoKu level it maps to: the higher
+//of the level the score falls in and the level of the hardest step. Steps
+//without a score in HoDoKuScores are ignored.
+func (self SolveDirections) HoDoKuRating() (int, HoDoKuLevel) {
+	score := 0
+	level := HODOKU_EASY
+
+	for _, step := range self.Steps() {
+		stepScore, ok := HoDoKuScores[step.TechniqueVariant()]
+		if !ok {
+			continue
+		}
+		score += stepScore.Score
+		if stepScore.Level > level {
+			level = stepScore.Level
+		}
+	}
+
+	scoreLevel := HODOKU_EXTREME
+	for _, item := range hodokuMaxScores {
+		if score <= item.maxScore {
+			scoreLevel = item.level
+			break
+		}
+	}
+
+	if scoreLevel > level {
+		level = scoreLevel
+	}
+
+	return score, level
+}
+
+func (self *gridImpl) HoDoKuRating() (int, HoDoKuLevel) {
+	directions := easiestSolution(self, hodokuScore)
+	if directions == nil {
+		return 0, HODOKU_EASY
+	}
+	return directions.HoDoKuRating()
+}
+
+func (self *mutableGridImpl) HoDoKuRating() (int, HoDoKuLevel) {
+	return self.Copy().HoDoKuRating()
+}
@@ -0,0 +1,121 @@
+package sudoku
+
+import (
+	"testing"
+)
+
+func TestHoDoKuScoresComplete(t *testing.T) {
+	for _, variant := range AllTechniqueVariants {
+		if _, ok := HoDoKuScores[variant]; !ok {
+			t.Error("No HoDoKu score for", variant)
+		}
+	}
+}
+
+func TestHoDoKuLevelString(t *testing.T) {
+	tests := map[HoDoKuLevel]string{
+		HODOKU_EASY:    "Easy",
+		HODOKU_MEDIUM:  "Medium",
+		HODOKU_HARD:    "Hard",
+		HODOKU_UNFAIR:  "Unfair",
+		HODOKU_EXTREME: "Extreme",
+	}
+	for level, expected := range tests {
+		if level.String() != expected {
+			t.Error("Wrong string for level", int(level), "got", level.String(), "expected", expected)
+		}
+	}
+}
+
+func TestHoDoKuRating(t *testing.T) {
+
+	stepsOf := func(variant string, count int) []*CompoundSolveStep {
+		var result []*CompoundSolveStep
+		for i := 0; i < count; i++ {
+			result = append(result, &CompoundSolveStep{
+				FillStep: &SolveStep{
+					Technique: techniquesByName[variant],
+				},
+			})
+		}
+		return result
+	}
+
+	tests := []struct {
+		steps         []*CompoundSolveStep
+		expectedScore int
+		expectedLevel HoDoKuLevel
+		description   string
+	}{
+		{
+			stepsOf("Only Legal Number", 10),
+			40,
+			HODOKU_EASY,
+			"Few easy steps",
+		},
+		{
+			stepsOf("Necessary In Row", 60),
+			840,
+			HODOKU_MEDIUM,
+			"Lots of easy steps",
+		},
+		{
+			append(stepsOf("Only Legal Number", 10), &CompoundSolveStep{
+				PrecursorSteps: []*SolveStep{
+					{
+						Technique: techniquesByName["XWing Row"],
+					},
+				},
+				FillStep: &SolveStep{
+					Technique: techniquesByName["Only Legal Number"],
+				},
+			}),
+			184,
+			HODOKU_HARD,
+			"Hard technique",
+		},
+		{
+			append(stepsOf("Necessary In Block", 50), stepsOf("Naked Quad Row", 8)...),
+			1660,
+			HODOKU_UNFAIR,
+			"Score in unfair",
+		},
+		{
+			stepsOf("Guess", 1),
+			10000,
+			HODOKU_EXTREME,
+			"Guess",
+		},
+	}
+
+	for _, test := range tests {
+		directions := SolveDirections{nil, test.steps}
+		score, level := directions.HoDoKuRating()
+		if score != test.expectedScore {
+			t.Error(test.description, "got wrong score", score, "expected", test.expectedScore)
+		}
+		if level != test.expectedLevel {
+			t.Error(test.description, "got wrong level", level, "expected", test.expectedLevel)
+		}
+	}
+
+	grid := LoadSDK(TEST_GRID)
+
+	score, level := grid.HoDoKuRating()
+
+	if score <= 0 || level != HODOKU_EASY {
+		t.Error("Easy grid got unexpected rating", score, level)
+	}
+
+	hardGrid, err := LoadSDKFromFile(puzzlePath("harddifficulty.sdk"))
+
+	if err != nil {
+		t.Fatal("Couldn't load hard grid")
+	}
+
+	hardScore, hardLevel := hardGrid.HoDoKuRating()
+
+	if hardScore <= score || hardLevel <= level {
+		t.Error("Hard grid got rating", hardScore, hardLevel, "no higher than easy grid", score, level)
+	}
+}
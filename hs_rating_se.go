@@ -0,0 +1,201 @@
+package sudoku
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+	This file implements an alternate way of rating puzzles that is
+	compatible with the community-standard Sudoku Explainer (SE) scale. Unlike
+	Difficulty(), which models how a real human would solve the puzzle, SE
+	solves the puzzle by always applying the easiest step available and rates
+	the puzzle by the hardest step it had to use.
+*/
+
+//SudokuExplainerRatings is the rating on the Sudoku Explainer scale for each
+//TechniqueVariant. The values are the SE ratings of the closest equivalent
+//SE technique, from 1.0 for the simplest singles up to 11.0 for guessing.
+var SudokuExplainerRatings = map[string]float64{
+	"Obvious In Block":         1.0,
+	"Obvious In Row":           1.0,
+	"Obvious In Col":           1.0,
+	"Necessary In Block":       1.2,
+	"Necessary In Row":         1.5,
+	"Necessary In Col":         1.5,
+	"Only Legal Number":        2.3,
+	"Pointing Pair Row":        2.6,
+	"Pointing Pair Col":        2.6,
+	"Block Block Interactions": 2.8,
+	"Naked Pair Block":         3.0,
+	"Naked Pair Row":           3.0,
+	"Naked Pair Col":           3.0,
+	"XWing Row":                3.2,
+	"XWing Col":                3.2,
+	"Hidden Pair Block":        3.4,
+	"Hidden Pair Row":          3.4,
+	"Hidden Pair Col":          3.4,
+	"Naked Triple Block":       3.6,
+	"Naked Triple Row":         3.6,
+	"Naked Triple Col":         3.6,
+	"Swordfish Row":            3.8,
+	"Swordfish Col":            3.8,
+	"Hidden Triple Block":      4.0,
+	"Hidden Triple Row":        4.0,
+	"Hidden Triple Col":        4.0,
+	"XYWing":                   4.2,
+	"XYWing (Same Block)":      4.2,
+	"Naked Quad Block":         5.0,
+	"Naked Quad Row":           5.0,
+	"Naked Quad Col":           5.0,
+	"Hidden Quad Block":        5.4,
+	"Hidden Quad Row":          5.4,
+	"Hidden Quad Col":          5.4,
+	"Forcing Chain (1 steps)":  7.0,
+	"Forcing Chain (2 steps)":  7.2,
+	"Forcing Chain (3 steps)":  7.4,
+	"Forcing Chain (4 steps)":  7.6,
+	"Forcing Chain (5 steps)":  7.8,
+	"Forcing Chain (6 steps)":  8.0,
+	"Pattern Overlay":          8.5,
+	"Pattern Overlay Fill":     8.5,
+	"Guess":                    11.0,
+}
+
+//sudokuExplainerRating returns the SE rating of the given variant, and
+//whether it has one.
+func sudokuExplainerRating(variant string) (float64, bool) {
+	rating, ok := SudokuExplainerRatings[variant]
+	return rating, ok
+}
+
+//SudokuExplainerRating returns the rating of the SolveDirections on the
+//Sudoku Explainer scale, which is the rating of the hardest step. Steps
+//without a rating in SudokuExplainerRatings are ignored.
+func (self SolveDirections) SudokuExplainerRating() float64 {
+	result := 0.0
+	for _, step := range self.Steps() {
+		if rating, ok := sudokuExplainerRating(step.TechniqueVariant()); ok {
+			result = math.Max(result, rating)
+		}
+	}
+	return result
+}
+
+func (self *gridImpl) SudokuExplainerRating() float64 {
+	directions := easiestSolution(self, sudokuExplainerRating)
+	if directions == nil {
+		return 0.0
+	}
+	return directions.SudokuExplainerRating()
+}
+
+func (self *mutableGridImpl) SudokuExplainerRating() float64 {
+	return self.Copy().SudokuExplainerRating()
+}
+
+//ratedTechnique is a technique, along with the lowest rating any of its
+//variants have.
+type ratedTechnique struct {
+	technique SolveTechnique
+	minRating float64
+}
+
+type ratedTechniquesByRating []ratedTechnique
+
+func (r ratedTechniquesByRating) Len() int {
+	return len(r)
+}
+
+func (r ratedTechniquesByRating) Swap(i, j int) {
+	r[i], r[j] = r[j], r[i]
+}
+
+func (r ratedTechniquesByRating) Less(i, j int) bool {
+	return r[i].minRating < r[j].minRating
+}
+
+//easiestSolution solves the grid by always applying the step with the lowest
+//rating, as determined by rater, and returns the SolveDirections. Both SE and
+//HoDoKu rate puzzles this way, which is why Grid's ratings use this instead
+//of rating the SolveDirections from HumanSolve, which don't always use the
+//easiest step available. Techniques whose variants the rater has no rating
+//for (for example, ones added with RegisterTechnique that aren't in
+//SudokuExplainerRatings or HoDoKuScores) are not used. Returns nil if the
+//grid doesn't have exactly one solution or can't be solved with the rated
+//techniques.
+func easiestSolution(grid Grid, rater func(variant string) (float64, bool)) *SolveDirections {
+
+	if grid.Solved() || !grid.HasSolution() || grid.HasMultipleSolutions() {
+		return nil
+	}
+
+	var techniques []ratedTechnique
+
+	for _, technique := range AllTechniques {
+		minRating := math.Inf(1)
+		for _, variant := range technique.Variants() {
+			if rating, ok := rater(variant); ok {
+				minRating = math.Min(minRating, rating)
+			}
+		}
+		if math.IsInf(minRating, 1) {
+			continue
+		}
+		techniques = append(techniques, ratedTechnique{technique, minRating})
+	}
+
+	sort.Stable(ratedTechniquesByRating(techniques))
+
+	workingGrid := grid.MutableCopy()
+
+	var compoundSteps []*CompoundSolveStep
+	var precursorSteps []*SolveStep
+
+	for !workingGrid.Solved() {
+
+		var bestStep *SolveStep
+		bestRating := math.Inf(1)
+
+		for _, item := range techniques {
+			//Techniques are sorted by their lowest rating, so once we've found
+			//a step at least this easy, none of the rest can beat it.
+			if bestStep != nil && item.minRating >= bestRating {
+				break
+			}
+
+			//If the technique only has one variant, all of its steps have the
+			//same rating so we only need one.
+			maxResults := 0
+			if len(item.technique.Variants()) == 1 {
+				maxResults = 1
+			}
+
+			for _, step := range item.technique.Candidates(workingGrid, maxResults) {
+				rating, ok := rater(step.TechniqueVariant())
+				if !ok {
+					continue
+				}
+				if rating < bestRating {
+					bestStep = step
+					bestRating = rating
+				}
+			}
+		}
+
+		if bestStep == nil {
+			return nil
+		}
+
+		bestStep.Apply(workingGrid)
+
+		precursorSteps = append(precursorSteps, bestStep)
+
+		if bestStep.Technique.IsFill() {
+			compoundSteps = append(compoundSteps, newCompoundSolveStep(precursorSteps))
+			precursorSteps = nil
+		}
+	}
+
+	return &SolveDirections{grid.Copy(), compoundSteps}
+}
@@ -0,0 +1,88 @@
+package sudoku
+
+import (
+	"testing"
+)
+
+func TestSudokuExplainerRatingsComplete(t *testing.T) {
+	for _, variant := range AllTechniqueVariants {
+		if _, ok := SudokuExplainerRatings[variant]; !ok {
+			t.Error("No Sudoku Explainer rating for", variant)
+		}
+	}
+}
+
+func TestSudokuExplainerRating(t *testing.T) {
+
+	grid := LoadSDK(TEST_GRID)
+
+	directions := easiestSolution(grid, sudokuExplainerRating)
+
+	if directions == nil {
+		t.Fatal("Couldn't find easiest solution")
+	}
+
+	solvedGrid := grid.MutableCopy()
+	for _, step := range directions.Steps() {
+		step.Apply(solvedGrid)
+	}
+
+	if !solvedGrid.Solved() {
+		t.Error("Easiest solution didn't solve the grid")
+	}
+
+	rating := grid.SudokuExplainerRating()
+
+	if rating < 1.0 || rating > 2.3 {
+		t.Error("Easy grid got an unexpected rating:", rating)
+	}
+
+	if rating != directions.SudokuExplainerRating() {
+		t.Error("Grid rating didn't match the easiest solution's rating")
+	}
+
+	hardGrid, err := LoadSDKFromFile(puzzlePath("harddifficulty.sdk"))
+
+	if err != nil {
+		t.Fatal("Couldn't load hard grid")
+	}
+
+	hardRating := hardGrid.SudokuExplainerRating()
+
+	if hardRating <= rating {
+		t.Error("Hard grid got a rating", hardRating, "no higher than easy grid's", rating)
+	}
+
+	if LoadSDK(TEST_GRID).MutableCopy().SudokuExplainerRating() != rating {
+		t.Error("Mutable grid got a different rating")
+	}
+
+	if NewGrid().SudokuExplainerRating() != 0.0 {
+		t.Error("Grid with multiple solutions got a rating")
+	}
+
+	sampleDirections := SolveDirections{
+		nil,
+		[]*CompoundSolveStep{
+			{
+				FillStep: &SolveStep{
+					Technique: techniquesByName["Necessary In Row"],
+				},
+			},
+			{
+				PrecursorSteps: []*SolveStep{
+					{
+						Technique: techniquesByName["XWing Col"],
+					},
+				},
+				FillStep: &SolveStep{
+					Technique: techniquesByName["Only Legal Number"],
+				},
+			},
+		},
+	}
+
+	if sampleDirections.SudokuExplainerRating() != 3.2 {
+		t.Error("Wrong rating for sample directions:", sampleDirections.SudokuExplainerRating())
+	}
+}
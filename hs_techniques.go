@@ -403,6 +403,22 @@ func init() {
 				4,
 			},
 		},
+		&patternOverlayTechnique{
+			&basicSolveTechnique{
+				"Pattern Overlay",
+				false,
+				_GROUP_NONE,
+				3,
+			},
+		},
+		&patternOverlayTechnique{
+			&basicSolveTechnique{
+				"Pattern Overlay Fill",
+				true,
+				_GROUP_NONE,
+				3,
+			},
+		},
 	}
 
 	GuessTechnique = &guessTechnique{
@@ -414,13 +430,25 @@ func init() {
 		},
 	}
 
+	indexTechniques()
+
+}
+
+//indexTechniques sorts Techniques and rebuilds all of the package-level
+//collections that are derived from it. It must be called whenever the set of
+//Techniques changes.
+func indexTechniques() {
 	//Sort Techniques in order of humanLikelihood
 	sort.Stable(techniqueByLikelihood(Techniques))
 
 	//Guess is always the highest, so AllTechniques should already be sorted.
-	AllTechniques = append(Techniques, GuessTechnique)
+	//Make sure AllTechniques doesn't share a backing array with Techniques.
+	AllTechniques = make([]SolveTechnique, 0, len(Techniques)+1)
+	AllTechniques = append(AllTechniques, Techniques...)
+	AllTechniques = append(AllTechniques, GuessTechnique)
 
 	techniquesByName = make(map[string]SolveTechnique)
+	AllTechniqueVariants = nil
 
 	for _, technique := range AllTechniques {
 		techniquesByName[technique.Name()] = technique
@@ -428,7 +456,6 @@ func init() {
 			AllTechniqueVariants = append(AllTechniqueVariants, variant)
 		}
 	}
-
 }
 
 func (self *basicSolveTechnique) Name() string {
@@ -60,6 +60,8 @@ func TestAllVariantNames(t *testing.T) {
 		"Hidden Quad Block",
 		"Hidden Quad Row",
 		"Hidden Quad Col",
+		"Pattern Overlay Fill",
+		"Pattern Overlay",
 		"Guess",
 	}
 	if !reflect.DeepEqual(expected, AllTechniqueVariants) {
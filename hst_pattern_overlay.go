@@ -0,0 +1,175 @@
+package sudoku
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+/*
+	Pattern overlay (also known as templates) is a single-digit technique
+	that is only useful when nothing else works. For a given digit there are
+	only 46656 ways to place all nine copies in an empty grid such that each
+	row, col, and block gets exactly one. Only some of those "templates" are
+	consistent with the cells already filled with the digit and the cells
+	where the digit is still possible. Any cell that no consistent template
+	covers can't have the digit, and any cell that every consistent template
+	covers must have it.
+*/
+
+type patternOverlayTechnique struct {
+	*basicSolveTechnique
+}
+
+//patternOverlayResult is the summary of all of the templates for a single
+//number that are consistent with the grid.
+type patternOverlayResult struct {
+	//The number of consistent templates
+	count int
+	//How many of the consistent templates put the number in each cell
+	coverage [DIM][DIM]int
+}
+
+func (self *patternOverlayTechnique) humanLikelihood(step *SolveStep) float64 {
+	//Enumerating templates is something almost no human does, so make sure this
+	//is only picked when nothing short of guessing works.
+	return self.difficultyHelper(2000.0)
+}
+
+func (self *patternOverlayTechnique) Description(step *SolveStep) string {
+	if len(step.TargetNums) == 0 {
+		return ""
+	}
+	num := step.TargetNums[0]
+	if self.IsFill() {
+		return fmt.Sprintf("every way of placing all of the %ds in the grid that fits with the cells that could still be %d puts a %d in %s, so it must go there", num, num, num, step.TargetCells.Description())
+	}
+	return fmt.Sprintf("no way of placing all of the %ds in the grid that fits with the cells that could still be %d puts a %d in %s, so it can't go there", num, num, num, step.TargetCells.Description())
+}
+
+func (self *patternOverlayTechnique) Candidates(grid Grid, maxResults int) []*SolveStep {
+	return self.candidatesHelper(self, grid, maxResults)
+}
+
+func (self *patternOverlayTechnique) find(grid Grid, coordinator findCoordinator) {
+
+	for _, i := range rand.Perm(DIM) {
+
+		if coordinator.shouldExitEarly() {
+			return
+		}
+
+		//i is zero indexed right now
+		i++
+
+		result := patternOverlay(grid, i)
+
+		if result.count == 0 {
+			//The grid is inconsistent for this number; there's nothing
+			//sensible we can say.
+			continue
+		}
+
+		//The cells that already have the number are the reason for every step.
+		var pointerCells CellRefSlice
+
+		for r := 0; r < DIM; r++ {
+			for c := 0; c < DIM; c++ {
+				cell := grid.Cell(r, c)
+				if cell.Number() == i {
+					pointerCells = append(pointerCells, cell.Reference())
+				}
+			}
+		}
+
+		var targetCells CellRefSlice
+
+		for r := 0; r < DIM; r++ {
+			for c := 0; c < DIM; c++ {
+				cell := grid.Cell(r, c)
+				if cell.Number() != 0 || !cell.Possible(i) {
+					continue
+				}
+				if self.IsFill() {
+					if result.coverage[r][c] == result.count {
+						//Fill steps are one cell at a time, and each gets its
+						//own copy of the pointer cells.
+						step := &SolveStep{self, CellRefSlice{cell.Reference()}, IntSlice{i}, append(CellRefSlice(nil), pointerCells...), nil, nil}
+						if step.IsUseful(grid) {
+							if coordinator.foundResult(step) {
+								return
+							}
+						}
+					}
+				} else if result.coverage[r][c] == 0 {
+					targetCells = append(targetCells, cell.Reference())
+				}
+			}
+		}
+
+		if self.IsFill() || len(targetCells) == 0 {
+			continue
+		}
+
+		step := &SolveStep{self, targetCells, IntSlice{i}, pointerCells, nil, nil}
+		if step.IsUseful(grid) {
+			if coordinator.foundResult(step) {
+				return
+			}
+		}
+	}
+}
+
+//patternOverlay enumerates every template for num that is consistent with
+//the grid and returns a summary of where they put num.
+func patternOverlay(grid Grid, num int) *patternOverlayResult {
+
+	var allowed [DIM][DIM]bool
+
+	for r := 0; r < DIM; r++ {
+		for c := 0; c < DIM; c++ {
+			cell := grid.Cell(r, c)
+			if cell.Number() == num {
+				allowed[r][c] = true
+			} else if cell.Number() == 0 && cell.Possible(num) {
+				allowed[r][c] = true
+			}
+		}
+	}
+
+	result := &patternOverlayResult{}
+
+	var cols [DIM]int
+	var colUsed [DIM]bool
+	var blockUsed [DIM]bool
+
+	var placeRow func(r int)
+
+	placeRow = func(r int) {
+		if r == DIM {
+			result.count++
+			for row, col := range cols {
+				result.coverage[row][col]++
+			}
+			return
+		}
+		for c := 0; c < DIM; c++ {
+			if !allowed[r][c] || colUsed[c] {
+				continue
+			}
+			block := (r/BLOCK_DIM)*BLOCK_DIM + c/BLOCK_DIM
+			if blockUsed[block] {
+				continue
+			}
+			cols[r] = c
+			colUsed[c] = true
+			blockUsed[block] = true
+			placeRow(r + 1)
+			colUsed[c] = false
+			blockUsed[block] = false
+		}
+	}
+
+	placeRow(0)
+
+	return result
+}
@@ -0,0 +1,156 @@
+package sudoku
+
+import (
+	"testing"
+)
+
+func TestPatternOverlayTemplateCount(t *testing.T) {
+	grid := NewGrid()
+
+	result := patternOverlay(grid, 1)
+
+	if result.count != 46656 {
+		t.Error("Wrong number of templates in an empty grid. Got", result.count, "expected 46656")
+	}
+
+	for r := 0; r < DIM; r++ {
+		for c := 0; c < DIM; c++ {
+			if result.coverage[r][c] != 46656/DIM {
+				t.Error("Cell", r, c, "was covered by the wrong number of templates:", result.coverage[r][c])
+			}
+		}
+	}
+}
+
+func TestPatternOverlayFill(t *testing.T) {
+	techniqueVariantsTestHelper(t, "Pattern Overlay Fill")
+
+	grid := NewGrid()
+
+	//Put a 1 in every block but the last.
+	for _, ref := range []CellRef{{0, 0}, {1, 3}, {2, 6}, {3, 1}, {4, 4}, {5, 7}, {6, 2}, {7, 5}} {
+		grid.MutableCell(ref.Row, ref.Col).SetNumber(1)
+	}
+
+	technique := techniquesByName["Pattern Overlay Fill"]
+
+	var step *SolveStep
+
+	for _, candidate := range technique.Candidates(grid, 0) {
+		if candidate.TargetNums[0] == 1 {
+			step = candidate
+		}
+	}
+
+	if step == nil {
+		t.Fatal("Pattern Overlay Fill didn't find the last 1")
+	}
+
+	if !step.TargetCells.sameAs(CellRefSlice{{8, 8}}) {
+		t.Error("Pattern Overlay Fill had wrong target cells:", step.TargetCells)
+	}
+
+	if !step.PointerCells.sameAs(CellRefSlice{{0, 0}, {1, 3}, {2, 6}, {3, 1}, {4, 4}, {5, 7}, {6, 2}, {7, 5}}) {
+		t.Error("Pattern Overlay Fill had wrong pointer cells:", step.PointerCells)
+	}
+
+	if technique.Description(step) != "every way of placing all of the 1s in the grid that fits with the cells that could still be 1 puts a 1 in (8,8), so it must go there" {
+		t.Error("Wrong description:", technique.Description(step))
+	}
+
+	//Every cell with the number is a pointer, even ones after the target.
+	grid = NewGrid()
+
+	pointerCells := CellRefSlice{{1, 3}, {2, 6}, {3, 1}, {4, 4}, {5, 7}, {6, 2}, {7, 5}, {8, 8}}
+
+	for _, ref := range pointerCells {
+		grid.MutableCell(ref.Row, ref.Col).SetNumber(1)
+	}
+
+	step = nil
+
+	for _, candidate := range technique.Candidates(grid, 0) {
+		if candidate.TargetNums[0] == 1 {
+			step = candidate
+		}
+	}
+
+	if step == nil {
+		t.Fatal("Pattern Overlay Fill didn't find the first 1")
+	}
+
+	if !step.TargetCells.sameAs(CellRefSlice{{0, 0}}) || !step.PointerCells.sameAs(pointerCells) {
+		t.Error("Pattern Overlay Fill had wrong cells:", step.TargetCells, step.PointerCells)
+	}
+}
+
+func TestPatternOverlay(t *testing.T) {
+	techniqueVariantsTestHelper(t, "Pattern Overlay")
+
+	//Nothing easier than Pattern Overlay (not even a Forcing Chain) makes
+	//progress on this grid.
+	grid, err := MutableLoadSDKFromFile(puzzlePath("patternoverlay1.sdk"))
+	if err != nil {
+		t.Fatal("Couldn't load puzzle")
+	}
+
+	for _, technique := range Techniques {
+		if technique.Name() == "Pattern Overlay" {
+			break
+		}
+		if technique.Name() == "Pattern Overlay Fill" {
+			continue
+		}
+		if steps := technique.Candidates(grid, 0); len(steps) != 0 {
+			t.Error(technique.Name(), "made progress on the pattern overlay grid:", steps[0])
+		}
+	}
+
+	tests := []multipleValidStepLoopOptions{
+		{
+			targetCells:  []CellRef{{2, 5}},
+			targetNums:   IntSlice([]int{9}),
+			pointerCells: []CellRef{{4, 0}, {6, 2}, {7, 4}, {8, 6}},
+			description:  "no way of placing all of the 9s in the grid that fits with the cells that could still be 9 puts a 9 in (2,5), so it can't go there",
+		},
+		{
+			targetCells:  []CellRef{{0, 5}},
+			targetNums:   IntSlice([]int{5}),
+			pointerCells: []CellRef{{1, 2}, {3, 1}, {6, 3}, {8, 0}},
+			description:  "no way of placing all of the 5s in the grid that fits with the cells that could still be 5 puts a 5 in (0,5), so it can't go there",
+		},
+	}
+
+	multipleValidStepsTestHelper(t, "patternoverlay1.sdk", "Pattern Overlay", tests)
+
+	for _, puzzleName := range []string{"harddifficulty.sdk", "harddifficulty2.sdk", "xwingtest.sdk", "swordfish_example.sdk"} {
+		grid, err := MutableLoadSDKFromFile(puzzlePath(puzzleName))
+		if err != nil {
+			t.Fatal("Couldn't load puzzle", puzzleName)
+		}
+
+		solvedGrid := grid.MutableCopy()
+		solvedGrid.Solve()
+
+		for _, techniqueName := range []string{"Pattern Overlay", "Pattern Overlay Fill"} {
+			technique := techniquesByName[techniqueName]
+
+			for _, step := range technique.Candidates(grid, 0) {
+				if len(step.TargetNums) != 1 {
+					t.Error(techniqueName, "returned a step with the wrong number of target nums:", step)
+					continue
+				}
+				num := step.TargetNums[0]
+				for _, ref := range step.TargetCells {
+					solvedNum := ref.Cell(solvedGrid).Number()
+					if technique.IsFill() && solvedNum != num {
+						t.Error(techniqueName, "in", puzzleName, "filled", num, "in", ref, "but the solution has", solvedNum)
+					}
+					if !technique.IsFill() && solvedNum == num {
+						t.Error(techniqueName, "in", puzzleName, "culled", num, "from", ref, "but that's the solution")
+					}
+				}
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package sudoku
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"math"
@@ -107,6 +108,15 @@ type HumanSolveOptions struct {
 	//NoGuess specifies that even if no other techniques work, the HumanSolve
 	//should not fall back on guessing, and instead just return failure.
 	NoGuess bool
+	//TwiddlerWeights overrides the weights of the named twiddlers (see
+	//TwiddlerNames) that HumanSolve uses to model which steps a human would
+	//notice first, which allows modeling different kinds of players. A weight
+	//of 0.0 disables the twiddler; a negative weight uses the twiddler's raw
+	//value. Twiddlers not in the map use their default weights. A value of
+	//nil uses the defaults for every twiddler. Names that aren't registered
+	//twiddlers make the options invalid, so HumanSolve, Hint, and
+	//HumanSolvePossibleSteps will return nil.
+	TwiddlerWeights map[string]float64
 
 	//TODO: figure out how to test that we do indeed use different values of
 	//numOptionsToCalculate.
@@ -148,8 +158,13 @@ func (s SolveDirections) Steps() []*SolveStep {
 }
 
 //Modifies the options object to make sure all of the options are set
-//in a legal way. Returns itself for convenience.
-func (self *HumanSolveOptions) validate() *HumanSolveOptions {
+//in a legal way. Returns an error if the options can't be made legal, for
+//example if TwiddlerWeights names a twiddler that doesn't exist.
+func (self *HumanSolveOptions) validate() error {
+
+	if err := self.validateTwiddlerWeights(); err != nil {
+		return err
+	}
 
 	if self.TechniquesToUse == nil {
 		self.TechniquesToUse = Techniques
@@ -172,10 +187,21 @@ func (self *HumanSolveOptions) validate() *HumanSolveOptions {
 
 	self.TechniquesToUse = techniques
 
-	return self
+	return nil
 
 }
 
+//validateTwiddlerWeights returns an error if TwiddlerWeights names a
+//twiddler that doesn't exist.
+func (self *HumanSolveOptions) validateTwiddlerWeights() error {
+	for name := range self.TwiddlerWeights {
+		if _, ok := TwiddlerWeight(name); !ok {
+			return errors.New("No twiddler named " + name)
+		}
+	}
+	return nil
+}
+
 //effectiveTechniquesToUse returns the effective list of techniques to use.
 //Basically just o.TechniquesToUse + Guess if NoGuess is not provided.
 func (o *HumanSolveOptions) effectiveTechniquesToUse() []SolveTechnique {
@@ -475,18 +501,53 @@ func (self *mutableGridImpl) Difficulty() float64 {
 	//Yes, this memoization will fail in the (rare!) cases where a grid's actual difficulty is 0.0, but
 	//the worst case scenario is that we just return the same value.
 	if self.cachedDifficulty == 0.0 {
-		self.cachedDifficulty = calcluateGridDifficulty(self, true)
+		//Go through the distribution so it's cached too, for callers who
+		//want more detail about the difficulty later.
+		self.cachedDifficulty = self.DifficultyDistribution().Mean
 	}
 	return self.cachedDifficulty
 }
 
+func (self *gridImpl) DifficultyExplanation() DifficultyExplanation {
+	return self.DifficultyDistribution().Explanation()
+}
+
+func (self *mutableGridImpl) DifficultyExplanation() DifficultyExplanation {
+	if self == nil {
+		return nil
+	}
+	return self.DifficultyDistribution().Explanation()
+}
+
+func (self *gridImpl) DifficultyDistribution() *DifficultyDistribution {
+	return calculateDifficultyDistribution(self, true)
+}
+
+func (self *mutableGridImpl) DifficultyDistribution() *DifficultyDistribution {
+	if self == nil {
+		return nil
+	}
+	if self.cachedDifficultyDistribution == nil {
+		self.cachedDifficultyDistribution = calculateDifficultyDistribution(self, true)
+		//Difficulty and DifficultyDistribution should agree.
+		self.cachedDifficulty = self.cachedDifficultyDistribution.Mean
+	}
+	return self.cachedDifficultyDistribution
+}
+
 func calcluateGridDifficulty(grid Grid, accurate bool) float64 {
+	return calculateDifficultyDistribution(grid, accurate).Mean
+}
+
+func calculateDifficultyDistribution(grid Grid, accurate bool) *DifficultyDistribution {
 	//This can be an extremely expensive method. Do not call repeatedly!
-	//returns the difficulty of the grid, which is a number between 0.0 and 1.0.
+	//returns the distribution of difficulties of the grid, each of which is a number between 0.0 and 1.0.
 	//This is a probabilistic measure; repeated calls may return different numbers, although generally we wait for the results to converge.
 
 	//We solve the same puzzle N times, then ask each set of steps for their difficulty, and combine those to come up with the overall difficulty.
 
+	result := &DifficultyDistribution{}
+
 	accum := 0.0
 	average := 0.0
 	lastAverage := 0.0
@@ -503,26 +564,31 @@ func calcluateGridDifficulty(grid Grid, accurate bool) float64 {
 	}
 
 	for i := 0; i < maxIterations; i++ {
-		difficulty := gridDifficultyHelper(grid)
+		signals := gridDifficultyHelper(grid)
+		difficulty := signals.difficulty()
+
+		result.addSample(difficulty, signals)
 
 		accum += difficulty
 		average = accum / (float64(i) + 1.0)
 
 		if math.Abs(average-lastAverage) < _DIFFICULTY_CONVERGENCE {
 			//Okay, we've already converged. Just return early!
-			return average
+			result.Converged = true
+			break
 		}
 
 		lastAverage = average
 	}
 
-	//We weren't converging... oh well!
-	return average
+	//If we weren't converging... oh well!
+	result.finish()
+	return result
 }
 
 //This function will HumanSolve _NUM_SOLVES_FOR_DIFFICULTY times, then average the signals together, then
 //give the difficulty for THAT. This is more accurate becuase the weights were trained on such averaged signals.
-func gridDifficultyHelper(grid Grid) float64 {
+func gridDifficultyHelper(grid Grid) DifficultySignals {
 
 	collector := make(chan DifficultySignals, _NUM_SOLVES_FOR_DIFFICULTY)
 	//Might as well run all of the human solutions in parallel
@@ -549,6 +615,6 @@ func gridDifficultyHelper(grid Grid) float64 {
 		combinedSignals[key] /= _NUM_SOLVES_FOR_DIFFICULTY
 	}
 
-	return combinedSignals.difficulty()
+	return combinedSignals
 
 }
@@ -104,6 +104,8 @@ type humanSolveSearcher struct {
 	grid                  Grid
 	options               *HumanSolveOptions
 	previousCompoundSteps []*CompoundSolveStep
+	//The twiddlers to use, with the weights from options applied.
+	twiddlers []probabilityTwiddlerItem
 }
 
 //twiddleRecord is a key/value pair in twiddles. We want to preserve ordering,
@@ -173,7 +175,9 @@ func humanSolveHelper(grid Grid, options *HumanSolveOptions, previousSteps []*Co
 		return nil
 	}
 
-	options.validate()
+	if options.validate() != nil {
+		return nil
+	}
 
 	snapshot := grid.Copy()
 
@@ -399,7 +403,7 @@ func (p *humanSolveItem) CreateNewItem(step *SolveStep) *humanSolveItem {
 	}
 	inProgressCompoundStep := p.Steps()
 	previousGrid := result.PreviousGrid()
-	for _, twiddler := range twiddlers {
+	for _, twiddler := range p.searcher.twiddlers {
 		tweak := twiddler.Twiddle(step, inProgressCompoundStep, p.searcher.previousCompoundSteps, previousGrid)
 		result.Twiddle(tweak, twiddler.name)
 	}
@@ -481,6 +485,7 @@ func newHumanSolveSearcher(grid Grid, previousCompoundSteps []*CompoundSolveStep
 		grid:                  grid,
 		options:               options,
 		previousCompoundSteps: previousCompoundSteps,
+		twiddlers:             effectiveTwiddlers(options),
 		done: make(chan bool),
 	}
 	heap.Init(&searcher.itemsToExplore)
@@ -774,6 +779,10 @@ func (n *humanSolveSearcherHeap) Pop() interface{} {
 func humanSolvePossibleStepsImpl(grid Grid, options *HumanSolveOptions, previousSteps []*CompoundSolveStep) (steps []*CompoundSolveStep, distribution ProbabilityDistribution) {
 	//TODO: with the new approach, we're getting a lot more extreme negative difficulty values. Train a new model!
 
+	if options != nil && options.validateTwiddlerWeights() != nil {
+		return nil, nil
+	}
+
 	//We send a copy here because our own selves will likely be modified soon
 	//after returning from this, and if the other threads haven't gotten the
 	//signal yet to shut down they might get in a weird state.
@@ -386,6 +386,7 @@ func TestHumanSolveOptionsMethods(t *testing.T) {
 		Techniques,
 		false,
 		nil,
+		nil,
 	}
 
 	options := DefaultHumanSolveOptions()
@@ -411,6 +412,7 @@ func TestHumanSolveOptionsMethods(t *testing.T) {
 		nil,
 		false,
 		nil,
+		nil,
 	}
 
 	validatedOptions := &HumanSolveOptions{
@@ -418,6 +420,7 @@ func TestHumanSolveOptionsMethods(t *testing.T) {
 		Techniques,
 		false,
 		nil,
+		nil,
 	}
 
 	weirdOptions.validate()
@@ -0,0 +1,304 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+/*
+Grids, SolveSteps, CompoundSolveSteps, and SolveDirections can all be
+encoded as JSON with encoding/json, and decoded back again. The encoding is
+meant to be stable, so it can be stored or read by other programs (like a
+web frontend rendering hints).
+
+A grid is an object with a "Cells" list of every cell, from left to right
+and top to bottom. Each cell is an object with the optional keys "Number",
+"Locked", "Marks", and "Excluded":
+
+	{"Cells": [{"Number": 6, "Locked": true}, {"Marks": [1, 2], "Excluded": [3]}, ...]}
+
+A SolveStep is an object with the name of its technique in "Technique", the
+variant of the technique in "Variant", and the optional keys "TargetCells",
+"TargetNums", "PointerCells", and "PointerNums". Cells are objects with a
+"Row" and "Col". When encoding, a human-readable "Description" is also
+included; it is ignored when decoding.
+
+A CompoundSolveStep is an object with "PrecursorSteps" (optional), and
+"FillStep". SolveDirections is an object with the "Grid" the directions are
+for, and the list of "CompoundSteps".
+*/
+
+type gridJSON struct {
+	Cells []cellJSON
+}
+
+type cellJSON struct {
+	Number   int      `json:",omitempty"`
+	Locked   bool     `json:",omitempty"`
+	Marks    IntSlice `json:",omitempty"`
+	Excluded IntSlice `json:",omitempty"`
+}
+
+type solveStepJSON struct {
+	Technique    string
+	Variant      string       `json:",omitempty"`
+	TargetCells  CellRefSlice `json:",omitempty"`
+	TargetNums   IntSlice     `json:",omitempty"`
+	PointerCells CellRefSlice `json:",omitempty"`
+	PointerNums  IntSlice     `json:",omitempty"`
+	//Only set when encoding, for the convenience of other programs.
+	Description string `json:",omitempty"`
+}
+
+type compoundSolveStepJSON struct {
+	PrecursorSteps   []*SolveStep `json:",omitempty"`
+	FillStep         *SolveStep
+	ScoreExplanation []string `json:",omitempty"`
+}
+
+type solveDirectionsJSON struct {
+	Grid          Grid
+	CompoundSteps []*CompoundSolveStep
+}
+
+//validJSONNumbers returns an error if any of numbers aren't between 1 and
+//DIM.
+func validJSONNumbers(numbers IntSlice) error {
+	for _, number := range numbers {
+		if number < 1 || number > DIM {
+			return errors.New("Invalid number: " + strconv.Itoa(number))
+		}
+	}
+	return nil
+}
+
+//validJSONCells returns an error if any of refs are outside of the grid.
+func validJSONCells(refs CellRefSlice) error {
+	for _, ref := range refs {
+		if ref.Row < 0 || ref.Row >= DIM || ref.Col < 0 || ref.Col >= DIM {
+			return errors.New("Invalid cell: " + ref.String())
+		}
+	}
+	return nil
+}
+
+//gridMarshalJSONImpl is the shared implementation of Grid.MarshalJSON.
+func gridMarshalJSONImpl(grid Grid) ([]byte, error) {
+	result := gridJSON{
+		Cells: make([]cellJSON, 0, DIM*DIM),
+	}
+
+	for _, cell := range grid.Cells() {
+		info := cellJSON{
+			Number: cell.Number(),
+			Locked: cell.Locked(),
+			Marks:  cell.Marks(),
+		}
+		for i := 1; i <= DIM; i++ {
+			if cell.Excluded(i) {
+				info.Excluded = append(info.Excluded, i)
+			}
+		}
+		result.Cells = append(result.Cells, info)
+	}
+
+	return json.Marshal(result)
+}
+
+func (self *gridImpl) MarshalJSON() ([]byte, error) {
+	return gridMarshalJSONImpl(self)
+}
+
+func (self *mutableGridImpl) MarshalJSON() ([]byte, error) {
+	return gridMarshalJSONImpl(self)
+}
+
+func (self *mutableGridImpl) UnmarshalJSON(data []byte) error {
+	var info gridJSON
+
+	if err := json.Unmarshal(data, &info); err != nil {
+		return err
+	}
+
+	if len(info.Cells) != DIM*DIM {
+		return errors.New("Grid did not have " + strconv.Itoa(DIM*DIM) + " cells")
+	}
+
+	//Check everything before changing anything, so a bad grid leaves self
+	//untouched.
+	for _, cell := range info.Cells {
+		if cell.Number < 0 || cell.Number > DIM {
+			return errors.New("Invalid number: " + strconv.Itoa(cell.Number))
+		}
+		if err := validJSONNumbers(cell.Marks); err != nil {
+			return err
+		}
+		if err := validJSONNumbers(cell.Excluded); err != nil {
+			return err
+		}
+	}
+
+	for i, info := range info.Cells {
+		cell := self.MutableCell(i/DIM, i%DIM)
+		cell.Unlock()
+		cell.SetNumber(info.Number)
+		cell.ResetMarks()
+		cell.ResetExcludes()
+		for _, mark := range info.Marks {
+			cell.SetMark(mark, true)
+		}
+		for _, excluded := range info.Excluded {
+			cell.SetExcluded(excluded, true)
+		}
+		if info.Locked {
+			cell.Lock()
+		}
+	}
+
+	return nil
+}
+
+//extraForVariant returns what a step using technique needs to store in
+//extra to be the given variant of the technique.
+func extraForVariant(technique SolveTechnique, variant string) (interface{}, error) {
+	step := &SolveStep{
+		Technique: technique,
+	}
+
+	//Forcing chains keep how many implication steps they took in extra.
+	if _, ok := technique.(*forcingChainsTechnique); ok {
+		for i := 1; i <= len(technique.Variants()); i++ {
+			step.extra = i
+			if variant == "" || technique.variant(step) == variant {
+				return i, nil
+			}
+		}
+	} else if variant == "" || technique.variant(step) == variant {
+		return nil, nil
+	}
+
+	return nil, errors.New("Unknown variant of " + technique.Name() + ": " + variant)
+}
+
+func (self *SolveStep) MarshalJSON() ([]byte, error) {
+	if self.Technique == nil {
+		return nil, errors.New("Step has no technique")
+	}
+	return json.Marshal(solveStepJSON{
+		Technique:    self.Technique.Name(),
+		Variant:      self.TechniqueVariant(),
+		TargetCells:  self.TargetCells,
+		TargetNums:   self.TargetNums,
+		PointerCells: self.PointerCells,
+		PointerNums:  self.PointerNums,
+		Description:  self.Description(),
+	})
+}
+
+func (self *SolveStep) UnmarshalJSON(data []byte) error {
+	var info solveStepJSON
+
+	if err := json.Unmarshal(data, &info); err != nil {
+		return err
+	}
+
+	technique, ok := techniquesByName[info.Technique]
+
+	if !ok {
+		return errors.New("Unknown technique: " + info.Technique)
+	}
+
+	extra, err := extraForVariant(technique, info.Variant)
+
+	if err != nil {
+		return err
+	}
+
+	for _, refs := range []CellRefSlice{info.TargetCells, info.PointerCells} {
+		if err := validJSONCells(refs); err != nil {
+			return err
+		}
+	}
+
+	for _, nums := range []IntSlice{info.TargetNums, info.PointerNums} {
+		if err := validJSONNumbers(nums); err != nil {
+			return err
+		}
+	}
+
+	*self = SolveStep{
+		Technique:    technique,
+		TargetCells:  info.TargetCells,
+		TargetNums:   info.TargetNums,
+		PointerCells: info.PointerCells,
+		PointerNums:  info.PointerNums,
+		extra:        extra,
+	}
+
+	return nil
+}
+
+func (c *CompoundSolveStep) MarshalJSON() ([]byte, error) {
+	return json.Marshal(compoundSolveStepJSON{
+		PrecursorSteps:   c.PrecursorSteps,
+		FillStep:         c.FillStep,
+		ScoreExplanation: c.explanation,
+	})
+}
+
+func (c *CompoundSolveStep) UnmarshalJSON(data []byte) error {
+	var info compoundSolveStepJSON
+
+	if err := json.Unmarshal(data, &info); err != nil {
+		return err
+	}
+
+	*c = CompoundSolveStep{
+		PrecursorSteps: info.PrecursorSteps,
+		FillStep:       info.FillStep,
+		explanation:    info.ScoreExplanation,
+	}
+
+	if !c.valid() {
+		return errors.New("Compound step was not valid")
+	}
+
+	return nil
+}
+
+func (self SolveDirections) MarshalJSON() ([]byte, error) {
+	return json.Marshal(solveDirectionsJSON{
+		Grid:          self.gridSnapshot,
+		CompoundSteps: self.CompoundSteps,
+	})
+}
+
+func (self *SolveDirections) UnmarshalJSON(data []byte) error {
+	//Grid is an interface, so decode it separately into a concrete grid.
+	var info struct {
+		Grid          json.RawMessage
+		CompoundSteps []*CompoundSolveStep
+	}
+
+	if err := json.Unmarshal(data, &info); err != nil {
+		return err
+	}
+
+	var snapshot Grid
+
+	if len(info.Grid) != 0 && string(info.Grid) != "null" {
+		grid := NewGrid()
+		if err := json.Unmarshal(info.Grid, grid); err != nil {
+			return err
+		}
+		snapshot = grid.Copy()
+	}
+
+	*self = SolveDirections{
+		gridSnapshot:  snapshot,
+		CompoundSteps: info.CompoundSteps,
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGridJSON(t *testing.T) {
+	grid := MutableLoadSDK(TEST_GRID)
+
+	grid.MutableCell(0, 3).SetMark(5, true)
+	grid.MutableCell(0, 3).SetMark(7, true)
+	grid.MutableCell(0, 4).SetExcluded(9, true)
+	grid.MutableCell(0, 5).SetNumber(8)
+
+	for _, original := range []Grid{grid, grid.Copy()} {
+		data, err := json.Marshal(original)
+
+		if err != nil {
+			t.Fatal("Couldn't marshal grid:", err)
+		}
+
+		if !strings.HasPrefix(string(data), `{"Cells":[{"Number":6,"Locked":true},{"Number":1,"Locked":true},{"Number":2,"Locked":true},{"Marks":[5,7]},{"Excluded":[9]},{"Number":8},`) {
+			t.Error("Grid JSON was wrong:", string(data))
+		}
+
+		//Decoding replaces everything that was in the grid before.
+		decoded := MutableLoad(SOLVED_TEST_GRID)
+		decoded.MutableCell(8, 8).SetMark(1, true)
+
+		if err := json.Unmarshal(data, decoded); err != nil {
+			t.Fatal("Couldn't unmarshal grid:", err)
+		}
+
+		if decoded.Diagram(true) != grid.Diagram(true) || decoded.Diagram(false) != grid.Diagram(false) {
+			t.Error("Decoded grid didn't match. Got", decoded.Diagram(true), "expected", grid.Diagram(true))
+		}
+
+		for i, cell := range decoded.Cells() {
+			if cell.Locked() != grid.Cells()[i].Locked() {
+				t.Error("Locks didn't match in", cell)
+			}
+		}
+	}
+
+	invalid := []string{
+		`{"Cells":[]}`,
+		`{"Cells":[` + strings.Repeat(`{"Number":10},`, DIM*DIM-1) + `{}]}`,
+		`{"Cells":[` + strings.Repeat(`{"Marks":[0]},`, DIM*DIM-1) + `{}]}`,
+		`{"Cells":[` + strings.Repeat(`{"Excluded":[10]},`, DIM*DIM-1) + `{}]}`,
+		`[1,2,3]`,
+	}
+
+	for i, data := range invalid {
+		decoded := MutableLoad(TEST_GRID)
+		if err := json.Unmarshal([]byte(data), decoded); err == nil {
+			t.Error("Invalid grid", i, "didn't give an error")
+		}
+		if decoded.DataString() != MutableLoad(TEST_GRID).DataString() {
+			t.Error("Invalid grid", i, "modified the grid")
+		}
+	}
+}
+
+func TestSolveStepJSON(t *testing.T) {
+	step := &SolveStep{
+		Technique:    techniquesByName["Pointing Pair Row"],
+		TargetCells:  CellRefSlice{{0, 3}, {0, 4}},
+		TargetNums:   IntSlice{3},
+		PointerCells: CellRefSlice{{1, 0}, {1, 1}},
+	}
+
+	data, err := json.Marshal(step)
+
+	if err != nil {
+		t.Fatal("Couldn't marshal step:", err)
+	}
+
+	expected := `{"Technique":"Pointing Pair Row","Variant":"Pointing Pair Row","TargetCells":[{"Row":0,"Col":3},{"Row":0,"Col":4}],"TargetNums":[3],"PointerCells":[{"Row":1,"Col":0},{"Row":1,"Col":1}],"Description":"` + step.Description() + `"}`
+
+	if string(data) != expected {
+		t.Error("Step JSON was wrong. Got", string(data), "expected", expected)
+	}
+
+	var decoded SolveStep
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal("Couldn't unmarshal step:", err)
+	}
+
+	if decoded.Technique != step.Technique || !decoded.TargetCells.sameAs(step.TargetCells) || !decoded.TargetNums.SameAs(step.TargetNums) || !decoded.PointerCells.sameAs(step.PointerCells) || len(decoded.PointerNums) != 0 {
+		t.Error("Decoded step didn't match. Got", decoded, "expected", step)
+	}
+
+	//Forcing chains store their variant in extra.
+	chains := &SolveStep{
+		Technique:   techniquesByName["Forcing Chain"],
+		TargetCells: CellRefSlice{{1, 0}},
+		TargetNums:  IntSlice{7},
+		extra:       4,
+	}
+
+	data, err = json.Marshal(chains)
+
+	if err != nil {
+		t.Fatal("Couldn't marshal forcing chain step:", err)
+	}
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal("Couldn't unmarshal forcing chain step:", err)
+	}
+
+	if decoded.TechniqueVariant() != "Forcing Chain (4 steps)" || decoded.extra != 4 {
+		t.Error("Forcing chain step lost its variant. Got", decoded.TechniqueVariant())
+	}
+
+	invalid := []string{
+		`{"Technique":"Not A Technique"}`,
+		`{"Technique":"Pointing Pair Row","Variant":"Forcing Chain (4 steps)"}`,
+		`{"Technique":"Forcing Chain","Variant":"Forcing Chain (100 steps)"}`,
+		`{"Technique":"Pointing Pair Row","TargetCells":[{"Row":9,"Col":0}]}`,
+		`{"Technique":"Pointing Pair Row","TargetNums":[0]}`,
+	}
+
+	for i, data := range invalid {
+		if err := json.Unmarshal([]byte(data), &decoded); err == nil {
+			t.Error("Invalid step", i, "didn't give an error")
+		}
+	}
+
+	if _, err := json.Marshal(&SolveStep{}); err == nil {
+		t.Error("Didn't get an error marshaling a step without a technique")
+	}
+}
+
+func TestSolveDirectionsJSON(t *testing.T) {
+	grid := MutableLoad(TEST_GRID)
+
+	directions := grid.HumanSolution(nil)
+
+	if directions == nil || len(directions.CompoundSteps) == 0 {
+		t.Fatal("Couldn't solve grid")
+	}
+
+	data, err := json.Marshal(directions)
+
+	if err != nil {
+		t.Fatal("Couldn't marshal directions:", err)
+	}
+
+	var decoded SolveDirections
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal("Couldn't unmarshal directions:", err)
+	}
+
+	if decoded.Grid().DataString() != grid.DataString() {
+		t.Error("Decoded directions had the wrong grid")
+	}
+
+	if decoded.Walkthrough() != directions.Walkthrough() {
+		t.Error("Decoded directions had a different walkthrough. Got", decoded.Walkthrough(), "expected", directions.Walkthrough())
+	}
+
+	redone, err := json.Marshal(decoded)
+
+	if err != nil {
+		t.Fatal("Couldn't marshal decoded directions:", err)
+	}
+
+	if string(redone) != string(data) {
+		t.Error("Directions didn't round trip")
+	}
+
+	//A compound step must end in a fill step.
+	invalid := `{"CompoundSteps":[{"FillStep":{"Technique":"Pointing Pair Row","TargetCells":[{"Row":0,"Col":0}],"TargetNums":[1]}}]}`
+
+	if err := json.Unmarshal([]byte(invalid), &decoded); err == nil {
+		t.Error("Didn't get an error for an invalid compound step")
+	}
+
+	if err := json.Unmarshal([]byte(`{"CompoundSteps":[]}`), &decoded); err != nil {
+		t.Error("Couldn't unmarshal directions without a grid:", err)
+	}
+
+	if decoded.Grid() != nil {
+		t.Error("Directions without a grid got a grid")
+	}
+}
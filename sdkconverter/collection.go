@@ -0,0 +1,251 @@
+package sdkconverter
+
+import (
+	"bufio"
+	"errors"
+	"github.com/jkomoros/sudoku"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/*
+A collection is a file of many puzzles. Puzzles whose format fits on a
+single line (like line or komo) may be one per line. Puzzles that take up
+multiple lines (like sdk, doku, ss, pm, or sadman) are separated from each
+other by blank lines. Both styles may be mixed in the same file.
+
+Each puzzle may be preceded by metadata lines of the form '#Key: value',
+where Key is one of Name, Source, or Rating (in any case). Other lines
+starting with '#' are treated as part of the puzzle, so sadman metadata is
+left for the sadman converter.
+*/
+
+//The metadata keys understood in collections.
+const _COLLECTION_NAME_KEY = "name"
+const _COLLECTION_SOURCE_KEY = "source"
+const _COLLECTION_RATING_KEY = "rating"
+
+//CollectionPuzzle is one puzzle in a collection, along with its optional
+//metadata.
+type CollectionPuzzle struct {
+	Grid   sudoku.MutableGrid
+	Name   string
+	Source string
+	//Rating is kept as a string since collections use many different scales.
+	Rating string
+}
+
+//CollectionReader reads puzzles one at a time from a collection, so that
+//collections too big to fit in memory can be processed.
+type CollectionReader struct {
+	format  Format
+	scanner *bufio.Scanner
+	//The line number of the last line read.
+	line int
+	//The metadata for the next puzzle.
+	pending CollectionPuzzle
+	//The lines of the multi-line puzzle being read.
+	block []string
+	//The line number the block started on.
+	blockStart int
+	done       bool
+}
+
+//CollectionWriter writes puzzles to a collection.
+type CollectionWriter struct {
+	format    Format
+	converter SudokuPuzzleConverter
+	writer    *bufio.Writer
+	//Whether anything has been written yet, and whether the last puzzle
+	//written took up multiple lines.
+	written          bool
+	lastWasMultiLine bool
+}
+
+//NewCollectionReader returns a CollectionReader that reads from r. If format
+//is "", the format of each puzzle is guessed with PuzzleFormat.
+func NewCollectionReader(r io.Reader, format Format) *CollectionReader {
+	scanner := bufio.NewScanner(r)
+	//Some formats, like pm, have long lines.
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &CollectionReader{
+		format:  format,
+		scanner: scanner,
+	}
+}
+
+//parseCollectionMetadata returns the key and value if line is a metadata
+//line.
+func parseCollectionMetadata(line string) (key string, value string, ok bool) {
+	if !strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	parts := strings.SplitN(line[1:], ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	key = strings.ToLower(strings.TrimSpace(parts[0]))
+	switch key {
+	case _COLLECTION_NAME_KEY, _COLLECTION_SOURCE_KEY, _COLLECTION_RATING_KEY:
+		return key, strings.TrimSpace(parts[1]), true
+	}
+	return "", "", false
+}
+
+//converterFor returns the converter to use for puzzle, or nil if there
+//isn't a valid one.
+func (self *CollectionReader) converterFor(puzzle string) SudokuPuzzleConverter {
+	format := self.format
+	if format == "" {
+		format = PuzzleFormat(puzzle)
+	}
+	converter := Converters[format]
+	if converter == nil || !converter.Valid(puzzle) {
+		return nil
+	}
+	return converter
+}
+
+//isSingleLinePuzzle returns true if line is a whole puzzle on its own.
+func (self *CollectionReader) isSingleLinePuzzle(line string) bool {
+	if self.format != "" {
+		return self.converterFor(line) != nil
+	}
+	return Converters[LineFormat].Valid(line) || Converters[KomoFormat].Valid(line)
+}
+
+//load returns the puzzle in data, with the pending metadata.
+func (self *CollectionReader) load(data string, lineNumber int) (*CollectionPuzzle, error) {
+	result := self.pending
+	self.pending = CollectionPuzzle{}
+
+	converter := self.converterFor(data)
+	if converter == nil {
+		return nil, errors.New("Invalid puzzle starting on line " + strconv.Itoa(lineNumber))
+	}
+
+	result.Grid = sudoku.NewGrid()
+	converter.Load(result.Grid, data)
+
+	return &result, nil
+}
+
+//endBlock returns the puzzle in the current block.
+func (self *CollectionReader) endBlock() (*CollectionPuzzle, error) {
+	data := strings.Join(self.block, "\n")
+	self.block = nil
+	return self.load(data, self.blockStart)
+}
+
+//Next returns the next puzzle in the collection. At the end of the
+//collection it returns nil and io.EOF. If a puzzle isn't valid, Next
+//returns nil and an error saying where it was, and the next call to Next
+//will continue with the following puzzle.
+func (self *CollectionReader) Next() (*CollectionPuzzle, error) {
+	for !self.done {
+		if !self.scanner.Scan() {
+			self.done = true
+			if err := self.scanner.Err(); err != nil {
+				return nil, err
+			}
+			break
+		}
+		self.line++
+		line := strings.TrimRight(self.scanner.Text(), " \t\r")
+
+		if line == "" {
+			if len(self.block) > 0 {
+				return self.endBlock()
+			}
+			continue
+		}
+
+		if len(self.block) == 0 {
+			if key, value, ok := parseCollectionMetadata(line); ok {
+				switch key {
+				case _COLLECTION_NAME_KEY:
+					self.pending.Name = value
+				case _COLLECTION_SOURCE_KEY:
+					self.pending.Source = value
+				case _COLLECTION_RATING_KEY:
+					self.pending.Rating = value
+				}
+				continue
+			}
+			if self.isSingleLinePuzzle(line) {
+				return self.load(line, self.line)
+			}
+			self.blockStart = self.line
+		}
+
+		self.block = append(self.block, line)
+	}
+
+	if len(self.block) > 0 {
+		return self.endBlock()
+	}
+
+	return nil, io.EOF
+}
+
+//NewCollectionWriter returns a CollectionWriter that writes puzzles in the
+//given format to w. Call Flush when done writing.
+func NewCollectionWriter(w io.Writer, format Format) *CollectionWriter {
+	return &CollectionWriter{
+		format:    format,
+		converter: Converters[format],
+		writer:    bufio.NewWriter(w),
+	}
+}
+
+//Write writes the puzzle, preceded by its metadata if it has any.
+func (self *CollectionWriter) Write(puzzle *CollectionPuzzle) error {
+	if self.converter == nil {
+		return errors.New("Unknown format: " + string(self.format))
+	}
+	if puzzle == nil || puzzle.Grid == nil {
+		return errors.New("No puzzle to write")
+	}
+
+	data := self.converter.DataString(puzzle.Grid)
+
+	if data == "" {
+		return errors.New("Puzzle can't be represented in format " + string(self.format))
+	}
+
+	var lines []string
+
+	if puzzle.Name != "" {
+		lines = append(lines, "#Name: "+puzzle.Name)
+	}
+	if puzzle.Source != "" {
+		lines = append(lines, "#Source: "+puzzle.Source)
+	}
+	if puzzle.Rating != "" {
+		lines = append(lines, "#Rating: "+puzzle.Rating)
+	}
+
+	lines = append(lines, data)
+
+	multiLine := strings.Contains(data, "\n")
+
+	//Multi-line puzzles need a blank line to separate them from the puzzles
+	//around them.
+	if self.written && (multiLine || self.lastWasMultiLine) {
+		if _, err := self.writer.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+
+	self.written = true
+	self.lastWasMultiLine = multiLine
+
+	_, err := self.writer.WriteString(strings.Join(lines, "\n") + "\n")
+	return err
+}
+
+//Flush writes any buffered puzzles to the underlying writer.
+func (self *CollectionWriter) Flush() error {
+	return self.writer.Flush()
+}
@@ -0,0 +1,174 @@
+package sdkconverter
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCollectionReader(t *testing.T) {
+	one := loadTestPuzzle("converter_one.sdk")
+	two := loadTestPuzzle("converter_two.sdk")
+	sadman := Load(loadTestPuzzle("sadman_complex.sdk")).DataString()
+
+	collection := strings.Join([]string{
+		"#Name: First",
+		"#rating: 2.6",
+		loadTestPuzzle("converter_one_line.sdk") + "  some trailing comment",
+		loadTestPuzzle("converter_one_komo.sdk"),
+		"",
+		"#Name: Third",
+		"#Source: The Test Suite",
+		two,
+		"",
+		"",
+		"1|2|3",
+		"4|5|6",
+		"",
+		loadTestPuzzle("ss_bordered.ss"),
+		loadTestPuzzle("sadman_complex.sdk"),
+	}, "\n")
+
+	reader := NewCollectionReader(strings.NewReader(collection), "")
+
+	expectations := []struct {
+		sdk    string
+		name   string
+		source string
+		rating string
+		err    bool
+	}{
+		{one, "First", "", "2.6", false},
+		{one, "", "", "", false},
+		{two, "Third", "The Test Suite", "", false},
+		{"", "", "", "", true},
+		{one, "", "", "", false},
+		{sadman, "", "", "", false},
+	}
+
+	for i, expectation := range expectations {
+		puzzle, err := reader.Next()
+		if expectation.err {
+			if err == nil || puzzle != nil {
+				t.Error("Puzzle", i, "didn't give an error")
+			} else if !strings.Contains(err.Error(), "line 19") {
+				t.Error("Error didn't say where the puzzle was:", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal("Puzzle", i, "gave error:", err)
+		}
+		if puzzle.Grid.DataString() != expectation.sdk {
+			t.Error("Puzzle", i, "was wrong. Got", puzzle.Grid.DataString(), "expected", expectation.sdk)
+		}
+		if puzzle.Name != expectation.name || puzzle.Source != expectation.source || puzzle.Rating != expectation.rating {
+			t.Error("Puzzle", i, "had wrong metadata:", puzzle.Name, puzzle.Source, puzzle.Rating)
+		}
+	}
+
+	if puzzle, err := reader.Next(); err != io.EOF || puzzle != nil {
+		t.Error("Didn't get EOF at end of collection. Got", puzzle, err)
+	}
+
+	if _, err := reader.Next(); err != io.EOF {
+		t.Error("Didn't keep getting EOF at end of collection")
+	}
+}
+
+func TestCollectionReaderFormat(t *testing.T) {
+	//A huge collection of one puzzle per line is read one at a time.
+	line := loadTestPuzzle("converter_one_line.sdk")
+
+	const count = 1000
+
+	reader := NewCollectionReader(strings.NewReader(strings.Repeat(line+"\n", count)), LineFormat)
+
+	for i := 0; i < count; i++ {
+		puzzle, err := reader.Next()
+		if err != nil {
+			t.Fatal("Got error reading puzzle", i, err)
+		}
+		if puzzle.Grid.DataString() != loadTestPuzzle("converter_one.sdk") {
+			t.Fatal("Puzzle", i, "was wrong")
+		}
+	}
+
+	if _, err := reader.Next(); err != io.EOF {
+		t.Error("Didn't get EOF after last puzzle")
+	}
+
+	//With a format given, puzzles in other formats aren't valid.
+	reader = NewCollectionReader(strings.NewReader(loadTestPuzzle("converter_one_komo.sdk")), LineFormat)
+
+	if _, err := reader.Next(); err == nil {
+		t.Error("Didn't get error for puzzle in wrong format")
+	}
+}
+
+func TestCollectionWriter(t *testing.T) {
+	one := Load(loadTestPuzzle("converter_one.sdk"))
+	two := Load(loadTestPuzzle("converter_two.sdk"))
+
+	puzzles := []*CollectionPuzzle{
+		{Grid: one, Name: "First", Rating: "0.5"},
+		{Grid: two},
+		{Grid: one, Source: "Somewhere"},
+	}
+
+	buffer := &bytes.Buffer{}
+
+	writer := NewCollectionWriter(buffer, LineFormat)
+
+	for _, puzzle := range puzzles {
+		if err := writer.Write(puzzle); err != nil {
+			t.Fatal("Got error writing puzzle:", err)
+		}
+	}
+
+	writer.Flush()
+
+	oneLine := loadTestPuzzle("converter_one_line.sdk")
+	twoLine := DataString(LineFormat, two)
+
+	expected := "#Name: First\n#Rating: 0.5\n" + oneLine + "\n" + twoLine + "\n#Source: Somewhere\n" + oneLine + "\n"
+
+	if buffer.String() != expected {
+		t.Error("Wrong line collection. Got\n", buffer.String(), "\nexpected\n", expected)
+	}
+
+	for _, format := range []Format{SDKFormat, SimpleSudokuFormat, DokuFormat, SadManFormat, PencilMarkFormat} {
+		buffer = &bytes.Buffer{}
+		writer = NewCollectionWriter(buffer, format)
+		for _, puzzle := range puzzles {
+			if err := writer.Write(puzzle); err != nil {
+				t.Fatal("Got error writing puzzle in format", format, err)
+			}
+		}
+		writer.Flush()
+
+		reader := NewCollectionReader(buffer, "")
+
+		for i, expected := range puzzles {
+			puzzle, err := reader.Next()
+			if err != nil {
+				t.Fatal("Got error reading back puzzle", i, "in format", format, err)
+			}
+			if puzzle.Grid.DataString() != expected.Grid.DataString() {
+				t.Error("Puzzle", i, "in format", format, "didn't round trip")
+			}
+			if puzzle.Name != expected.Name || puzzle.Source != expected.Source || puzzle.Rating != expected.Rating {
+				t.Error("Puzzle", i, "in format", format, "had wrong metadata")
+			}
+		}
+
+		if _, err := reader.Next(); err != io.EOF {
+			t.Error("Extra puzzles read back in format", format)
+		}
+	}
+
+	if err := NewCollectionWriter(buffer, "foo").Write(puzzles[0]); err == nil {
+		t.Error("Didn't get error writing unknown format")
+	}
+}
@@ -1,8 +1,12 @@
 /*
 Package sdkconverter provides a set of converters to and from sudoku's default sdk format.
 
-It supports three file types: 'sdk', 'doku', and 'komo'. To help ensure you're
-using a supported format, pass FooFormat instead of the direct string.
+It supports seven file types: 'sdk', 'doku', 'komo', 'sadman', 'line', 'ss',
+and 'pm'. To help ensure you're using a supported format, pass FooFormat
+instead of the direct string.
+
+Files of many puzzles can be read one puzzle at a time with a
+CollectionReader, and written with a CollectionWriter.
 */
 package sdkconverter
 
@@ -99,6 +103,10 @@ func init() {
 	Converters["komo"] = &komoConverter{}
 	Converters["sdk"] = &sdkConverter{}
 	Converters["doku"] = &dokuConverter{}
+	Converters[SadManFormat] = &sadManConverter{}
+	Converters[LineFormat] = &lineConverter{}
+	Converters[SimpleSudokuFormat] = &simpleSudokuConverter{}
+	Converters[PencilMarkFormat] = &pencilMarkConverter{}
 }
 
 //DataString is a convenience method that returns the given grid's string
@@ -147,9 +155,29 @@ func ToOther(format Format, sdk string) (other string) {
 	return converter.DataString(grid)
 }
 
+//The order PuzzleFormat tries formats in. Some puzzles are valid in more
+//than one format (for example, every line is also a valid sdk, and every sdk
+//is a valid doku), so the most specific formats go first. SadMan files
+//without metadata or sections are sdk files too, so sdk comes first.
+var formatDetectionOrder = []Format{
+	KomoFormat,
+	LineFormat,
+	SimpleSudokuFormat,
+	SDKFormat,
+	SadManFormat,
+	PencilMarkFormat,
+	DokuFormat,
+}
+
 //PuzzleFormat returns the most likely format type for the provided puzzle
 //string, or "" if none are valid.
 func PuzzleFormat(puzzle string) Format {
+	for _, format := range formatDetectionOrder {
+		if converter := Converters[format]; converter != nil && converter.Valid(puzzle) {
+			return format
+		}
+	}
+	//Any other converters that have been added
 	for format, converter := range Converters {
 		if converter.Valid(puzzle) {
 			return format
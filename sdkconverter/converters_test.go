@@ -3,6 +3,8 @@ package sdkconverter
 import (
 	"github.com/jkomoros/sudoku"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -162,6 +164,317 @@ func TestSDKConverterValid(t *testing.T) {
 	validTestHelper(t, "sdk", "nakedpair3.sdk", true)
 }
 
+func TestSadManConverterValid(t *testing.T) {
+	validTestHelper(t, "sadman", "sadman_complex.sdk", true)
+	validTestHelper(t, "sadman", "sadman_simple.sdk", true)
+	validTestHelper(t, "sadman", "invalid_sadman_too_short.sdk", false)
+	validTestHelper(t, "sadman", "converter_one.sdk", false)
+	validTestHelper(t, "sadman", "converter_one_komo.sdk", false)
+	validTestHelper(t, "sadman", "doku_complex.doku", false)
+}
+
+func TestSadManConverterLoad(t *testing.T) {
+	converterTesterHelper(t, true, "sadman", "sadman_simple.sdk", "converter_one.sdk")
+
+	grid := Load(loadTestPuzzle("sadman_simple.sdk"))
+
+	if !grid.Cell(0, 1).Locked() {
+		t.Error("Clue wasn't locked")
+	}
+
+	//Should have the same locks, user-filled numbers, and marks as the
+	//complex doku.
+	grid = Load(loadTestPuzzle("sadman_complex.sdk"))
+
+	expected := loadTestPuzzle("doku_complex_normalized.doku")
+
+	if dokuDataString := DataString("doku", grid); dokuDataString != expected {
+		t.Error("Loading complex sadman got wrong grid. Got", dokuDataString, "expected", expected)
+	}
+
+	metadata := LoadSadManMetadata(loadTestPuzzle("sadman_complex.sdk"))
+
+	expectedMetadata := SadManMetadata{
+		Author:      "Jane Doe",
+		Description: "A test puzzle",
+		Date:        "2005-03-08",
+		Source:      "Sudoku Weekly",
+		Level:       "Hard",
+	}
+
+	if metadata == nil || *metadata != expectedMetadata {
+		t.Error("Got wrong metadata. Got", metadata, "expected", expectedMetadata)
+	}
+
+	if LoadSadManMetadata(loadTestPuzzle("converter_one.sdk")) != nil {
+		t.Error("Got metadata for a puzzle that isn't sadman")
+	}
+}
+
+func TestSadManConverterLoadWithoutSections(t *testing.T) {
+	converter := Converters["sadman"]
+
+	const directory = "../puzzles/sadman/"
+
+	entries, err := os.ReadDir(directory)
+
+	if err != nil {
+		t.Fatal("Couldn't read sadman puzzles:", err)
+	}
+
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".sdk") {
+			continue
+		}
+
+		data, err := os.ReadFile(directory + entry.Name())
+
+		if err != nil {
+			t.Fatal("Couldn't read", entry.Name(), err)
+		}
+
+		puzzle := string(data)
+
+		if !converter.Valid(puzzle) {
+			t.Error("Sadman puzzle", entry.Name(), "wasn't valid")
+			continue
+		}
+
+		grid := sudoku.NewGrid()
+
+		if !converter.Load(grid, puzzle) {
+			t.Error("Couldn't load sadman puzzle", entry.Name())
+			continue
+		}
+
+		expected := sudoku.NewGrid()
+		expected.LoadSDK(puzzle)
+
+		if grid.DataString() != expected.DataString() {
+			t.Error("Sadman puzzle", entry.Name(), "loaded wrong. Got", grid.DataString(), "expected", expected.DataString())
+		}
+	}
+
+	//Metadata can come before the rows.
+	puzzle := "#AJane Doe\n" + strings.Join(strings.Split(loadTestPuzzle("sadman_simple.sdk"), "\n")[1:], "\n")
+
+	if metadata := LoadSadManMetadata(puzzle); metadata == nil || metadata.Author != "Jane Doe" {
+		t.Error("Didn't load metadata before rows without a section. Got", metadata)
+	}
+
+	if DataString("sdk", Load(puzzle)) != loadTestPuzzle("converter_one.sdk") {
+		t.Error("Loaded sadman puzzle with metadata and no sections wrong:", DataString("sdk", Load(puzzle)))
+	}
+}
+
+func TestSadManConverterDataString(t *testing.T) {
+	converterTesterHelper(t, false, "sadman", "sadman_simple.sdk", "converter_one.sdk")
+
+	puzzle := loadTestPuzzle("sadman_complex.sdk")
+
+	grid := Load(puzzle)
+
+	if dataString := SadManDataString(grid, LoadSadManMetadata(puzzle)); dataString != puzzle {
+		t.Error("Failed round trip with complex sadman. Got", dataString, "\nExpected", puzzle)
+	}
+}
+
+func TestLineConverterValid(t *testing.T) {
+	validTestHelper(t, "line", "converter_one_line.sdk", true)
+	validTestHelper(t, "line", "line_with_comment.sdk", true)
+	validTestHelper(t, "line", "converter_one.sdk", false)
+	//An sdk with no separators is the same as a line.
+	validTestHelper(t, "line", "sdk_no_sep.sdk", true)
+	validTestHelper(t, "line", "converter_one_ss.ss", false)
+}
+
+func TestLineConverterLoad(t *testing.T) {
+	converterTesterHelper(t, true, "line", "converter_one_line.sdk", "converter_one.sdk")
+	converterTesterHelper(t, true, "line", "line_with_comment.sdk", "converter_one.sdk")
+}
+
+func TestLineConverterDataString(t *testing.T) {
+	converterTesterHelper(t, false, "line", "converter_one_line.sdk", "converter_one.sdk")
+}
+
+func TestSimpleSudokuConverterValid(t *testing.T) {
+	validTestHelper(t, "ss", "converter_one_ss.ss", true)
+	validTestHelper(t, "ss", "ss_bordered.ss", true)
+	validTestHelper(t, "ss", "converter_one.sdk", false)
+	validTestHelper(t, "ss", "converter_one_line.sdk", false)
+	validTestHelper(t, "ss", "sadman_simple.sdk", false)
+}
+
+func TestSimpleSudokuConverterLoad(t *testing.T) {
+	converterTesterHelper(t, true, "ss", "converter_one_ss.ss", "converter_one.sdk")
+	converterTesterHelper(t, true, "ss", "ss_bordered.ss", "converter_one.sdk")
+}
+
+func TestSimpleSudokuConverterDataString(t *testing.T) {
+	converterTesterHelper(t, false, "ss", "converter_one_ss.ss", "converter_one.sdk")
+}
+
+func TestPencilMarkConverterValid(t *testing.T) {
+	validTestHelper(t, "pm", "pencil_marks.pm", true)
+	validTestHelper(t, "pm", "converter_one.sdk", false)
+	validTestHelper(t, "pm", "converter_one_line.sdk", false)
+	validTestHelper(t, "pm", "converter_one_ss.ss", false)
+	validTestHelper(t, "pm", "doku_complex.doku", false)
+
+	for _, format := range []Format{"sdk", "doku", "ss", "line"} {
+		validTestHelper(t, format, "pencil_marks.pm", false)
+	}
+}
+
+func TestPencilMarkConverterLoad(t *testing.T) {
+	puzzle := loadTestPuzzle("pencil_marks.pm")
+
+	grid := Load(puzzle)
+
+	//The numbers and marks are the same as the complex doku.
+	expected := Load(loadTestPuzzle("doku_complex.doku"))
+	for _, cell := range expected.MutableCells() {
+		cell.Unlock()
+	}
+
+	if dokuDataString := DataString("doku", grid); dokuDataString != DataString("doku", expected) {
+		t.Error("Loading pencil marks got wrong grid. Got", dokuDataString, "expected", DataString("doku", expected))
+	}
+
+	if !grid.Cell(1, 4).Possibilities().SameContentAs(sudoku.IntSlice{1, 7, 8, 9}) {
+		t.Error("Loading pencil marks didn't exclude candidates. Got", grid.Cell(1, 4).Possibilities())
+	}
+
+	if !grid.Cell(2, 0).Marks().SameContentAs(sudoku.IntSlice{1, 2}) {
+		t.Error("Loading pencil marks didn't load marks. Got", grid.Cell(2, 0).Marks())
+	}
+
+	if hint := grid.Hint(nil, nil); hint == nil || len(hint.CompoundSteps) == 0 {
+		t.Error("Couldn't get a hint for loaded pencil marks")
+	}
+
+	//Borders and separators are optional.
+	var plainLines []string
+	for _, line := range strings.Split(puzzle, "\n") {
+		if strings.Trim(line, ".:'+-|") == "" {
+			continue
+		}
+		plainLines = append(plainLines, strings.Replace(line, "|", "", -1))
+	}
+
+	plain := strings.Join(plainLines, "\n")
+
+	if PuzzleFormat(plain) != "pm" {
+		t.Fatal("Pencil marks without borders weren't detected")
+	}
+
+	if DataString("pm", Load(plain)) != puzzle {
+		t.Error("Pencil marks without borders loaded differently")
+	}
+}
+
+func TestPencilMarkConverterLoadHoDoKu(t *testing.T) {
+	//A grid in HoDoKu's PM grid format, with a naked single in (4,1).
+	//HoDoKu doesn't mark which cells are filled.
+	puzzle := loadTestPuzzle("hodoku.pm")
+
+	if PuzzleFormat(puzzle) != "pm" {
+		t.Fatal("HoDoKu pencil marks weren't detected")
+	}
+
+	grid := Load(puzzle)
+
+	expected := Load(loadTestPuzzle("converter_one.sdk"))
+
+	if grid.DataString() != expected.DataString() {
+		t.Error("Loading HoDoKu pencil marks got wrong filled cells. Got", grid.DataString(), "expected", expected.DataString())
+	}
+
+	if !grid.Cell(4, 1).Possibilities().SameContentAs(sudoku.IntSlice{8}) {
+		t.Error("Naked single had wrong possibilities:", grid.Cell(4, 1).Possibilities())
+	}
+
+	hint := grid.Hint(nil, nil)
+
+	if hint == nil || len(hint.CompoundSteps) == 0 {
+		t.Fatal("Couldn't get a hint for HoDoKu pencil marks")
+	}
+
+	for _, ref := range hint.CompoundSteps[len(hint.CompoundSteps)-1].FillStep.TargetCells {
+		if expected.Cell(ref.Row, ref.Col).Number() != 0 {
+			t.Error("Hint filled a given:", ref)
+		}
+	}
+
+	//Once written back out, the filled cells are marked.
+	if !strings.Contains(DataString("pm", grid), "| *3 ") {
+		t.Error("Filled cells weren't marked:", DataString("pm", grid))
+	}
+
+	if DataString("pm", Load(DataString("pm", grid))) != DataString("pm", grid) {
+		t.Error("HoDoKu pencil marks didn't round trip")
+	}
+}
+
+func TestPencilMarkConverterDataString(t *testing.T) {
+	puzzle := loadTestPuzzle("pencil_marks.pm")
+
+	if dataString := DataString("pm", Load(puzzle)); dataString != puzzle {
+		t.Error("Failed round trip with pencil marks. Got\n", dataString, "\nExpected\n", puzzle)
+	}
+
+	//An unfilled cell with a single candidate left stays unfilled, so the
+	//naked single is still there to be found.
+	grid := Load(loadTestPuzzle("converter_one.sdk"))
+	grid.Solve()
+
+	cell := grid.MutableCell(4, 4)
+	number := cell.Number()
+	cell.SetNumber(0)
+
+	loaded := Load(DataString("pm", grid))
+
+	if loaded == nil {
+		t.Fatal("Couldn't load pencil marks with a naked single")
+	}
+
+	if loaded.Cell(4, 4).Number() != 0 {
+		t.Error("Unfilled cell with one candidate was loaded as filled")
+	}
+
+	if !loaded.Cell(4, 4).Possibilities().SameContentAs(sudoku.IntSlice{number}) {
+		t.Error("Unfilled cell with one candidate had wrong possibilities:", loaded.Cell(4, 4).Possibilities())
+	}
+
+	if loaded.Cell(0, 0).Number() != grid.Cell(0, 0).Number() {
+		t.Error("Filled cell wasn't loaded as filled")
+	}
+
+	hint := loaded.Hint(nil, nil)
+
+	if hint == nil || len(hint.CompoundSteps) == 0 {
+		t.Fatal("Couldn't get a hint for the naked single")
+	}
+
+	fillStep := hint.CompoundSteps[len(hint.CompoundSteps)-1].FillStep
+
+	if len(fillStep.TargetCells) != 1 || fillStep.TargetCells[0] != (sudoku.CellRef{Row: 4, Col: 4}) || !fillStep.TargetNums.SameContentAs(sudoku.IntSlice{number}) {
+		t.Error("Hint didn't fill the naked single. Got", fillStep)
+	}
+
+	//A grid with no excludes shows every possibility.
+	grid = Load(loadTestPuzzle("converter_one.sdk"))
+
+	firstCell := ""
+	for _, num := range grid.Cell(0, 0).Possibilities() {
+		firstCell += strconv.Itoa(num)
+	}
+
+	if !strings.HasPrefix(strings.Split(DataString("pm", grid), "\n")[1], "| "+firstCell+" ") {
+		t.Error("Pencil marks for a grid without excludes were wrong:", DataString("pm", grid))
+	}
+}
+
 func validTestHelper(t *testing.T, format Format, file string, expected bool) {
 	converter := Converters[format]
 
@@ -239,8 +552,8 @@ func TestLoadInto(t *testing.T) {
 
 func TestFormat(t *testing.T) {
 	result := PuzzleFormat(loadTestPuzzle("converter_one.sdk"))
-	//doku and sdk are both valid options
-	if result != "sdk" && result != "doku" {
+	//doku and sdk are both valid, but sdk is more specific
+	if result != "sdk" {
 		t.Error("Format guessed wrong format:", result)
 	}
 	result = PuzzleFormat(loadTestPuzzle("converter_one_komo.sdk"))
@@ -251,6 +564,22 @@ func TestFormat(t *testing.T) {
 	if result != "doku" {
 		t.Error("Format guessed wrong format for doku puzzle: ", result)
 	}
+	result = PuzzleFormat(loadTestPuzzle("sadman_complex.sdk"))
+	if result != "sadman" {
+		t.Error("Format guessed wrong format for sadman puzzle: ", result)
+	}
+	result = PuzzleFormat(loadTestPuzzle("converter_one_line.sdk"))
+	if result != "line" {
+		t.Error("Format guessed wrong format for line puzzle: ", result)
+	}
+	result = PuzzleFormat(loadTestPuzzle("ss_bordered.ss"))
+	if result != "ss" {
+		t.Error("Format guessed wrong format for ss puzzle: ", result)
+	}
+	result = PuzzleFormat(loadTestPuzzle("pencil_marks.pm"))
+	if result != "pm" {
+		t.Error("Format guessed wrong format for pencil mark puzzle: ", result)
+	}
 	result = PuzzleFormat(loadTestPuzzle("invalid_sdk_too_short.sdk"))
 	if result != "" {
 		t.Error("Format guessed wrong format for an unknown puzzle type", result)
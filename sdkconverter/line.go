@@ -0,0 +1,170 @@
+package sdkconverter
+
+import (
+	"github.com/jkomoros/sudoku"
+	"strconv"
+	"strings"
+)
+
+/*
+LineFormat is the format most large puzzle collections are distributed in: a
+single line of DIM * DIM characters, one per cell, read row by row. Each
+character is a number 1-9, or a '.' or '0' for an unfilled cell. Anything
+after the cells, separated by whitespace (often a rating or a name), is
+ignored. Like sdk, it does not support marks, locks, or user-filled numbers.
+*/
+const LineFormat Format = "line"
+
+/*
+SimpleSudokuFormat is the .ss format used by Simple Sudoku and many puzzle
+sites. Each row is on its own line, with the cells of each block separated by
+'|', and the rows of each block separated by a line like '---+---+---'.
+Unfilled cells are '.', '0', or 'X'. Borders around the grid (lines like
+'*-----------*', and '|' at the start and end of rows) are allowed and
+ignored. It does not support marks, locks, or user-filled numbers.
+*/
+const SimpleSudokuFormat Format = "ss"
+
+type lineConverter struct {
+}
+
+type simpleSudokuConverter struct {
+}
+
+//validLineCells returns true if cells is a valid list of every cell in the
+//grid, as in LineFormat and SimpleSudokuFormat.
+func validLineCells(cells string, allowX bool) bool {
+	if len(cells) != sudoku.DIM*sudoku.DIM {
+		return false
+	}
+	for _, ch := range cells {
+		if ch == '.' || (ch >= '0' && ch <= '9') || (allowX && ch == 'X') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+//loadLineCells fills grid with cells, a list of every cell in the grid in
+//the same form as LineFormat.
+func loadLineCells(grid sudoku.MutableGrid, cells string) {
+	for i, ch := range cells {
+		number, err := strconv.Atoi(string(ch))
+		if err != nil {
+			number = 0
+		}
+		cellInfo{number: number}.fillCell(grid.MutableCell(i/sudoku.DIM, i%sudoku.DIM))
+	}
+}
+
+//lineCells returns the cells of puzzle in LineFormat, or "" if it isn't
+//valid.
+func lineCells(puzzle string) string {
+	puzzle = strings.TrimSpace(puzzle)
+	if strings.ContainsAny(puzzle, "\n\r") {
+		return ""
+	}
+	fields := strings.Fields(puzzle)
+	if len(fields) == 0 || !validLineCells(fields[0], false) {
+		return ""
+	}
+	return fields[0]
+}
+
+func (c *lineConverter) Valid(puzzle string) bool {
+	return lineCells(puzzle) != ""
+}
+
+func (c *lineConverter) Load(grid sudoku.MutableGrid, puzzle string) bool {
+	cells := lineCells(puzzle)
+	if cells == "" {
+		return false
+	}
+	loadLineCells(grid, cells)
+	return true
+}
+
+func (c *lineConverter) DataString(grid sudoku.Grid) string {
+	result := ""
+	for _, cell := range grid.Cells() {
+		if cell.Number() == 0 {
+			result += "."
+		} else {
+			result += strconv.Itoa(cell.Number())
+		}
+	}
+	return result
+}
+
+//simpleSudokuCells returns the cells of puzzle in SimpleSudokuFormat, in the
+//same form as LineFormat, or "" if it isn't valid.
+func simpleSudokuCells(puzzle string) string {
+	cells := ""
+	separators := 0
+
+	for _, line := range strings.Split(puzzle, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.Trim(line, "-+*|") == "" {
+			if strings.Contains(line, "+") {
+				separators++
+			}
+			continue
+		}
+		row := strings.Replace(strings.Trim(line, "|"), "|", "", -1)
+		if len(row) != sudoku.DIM {
+			return ""
+		}
+		cells += row
+	}
+
+	//Without the separators it's just an sdk.
+	if separators != sudoku.BLOCK_DIM-1 || !validLineCells(cells, true) {
+		return ""
+	}
+
+	return cells
+}
+
+func (c *simpleSudokuConverter) Valid(puzzle string) bool {
+	return simpleSudokuCells(puzzle) != ""
+}
+
+func (c *simpleSudokuConverter) Load(grid sudoku.MutableGrid, puzzle string) bool {
+	cells := simpleSudokuCells(puzzle)
+	if cells == "" {
+		return false
+	}
+	loadLineCells(grid, cells)
+	return true
+}
+
+func (c *simpleSudokuConverter) DataString(grid sudoku.Grid) string {
+	var rows []string
+	for r := 0; r < sudoku.DIM; r++ {
+		if r != 0 && r%sudoku.BLOCK_DIM == 0 {
+			var separator []string
+			for i := 0; i < sudoku.DIM/sudoku.BLOCK_DIM; i++ {
+				separator = append(separator, strings.Repeat("-", sudoku.BLOCK_DIM))
+			}
+			rows = append(rows, strings.Join(separator, "+"))
+		}
+		row := ""
+		for c := 0; c < sudoku.DIM; c++ {
+			if c != 0 && c%sudoku.BLOCK_DIM == 0 {
+				row += "|"
+			}
+			cell := grid.Cell(r, c)
+			if cell.Number() == 0 {
+				row += "."
+			} else {
+				row += strconv.Itoa(cell.Number())
+			}
+		}
+		rows = append(rows, row)
+	}
+	return strings.Join(rows, "\n")
+}
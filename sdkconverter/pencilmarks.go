@@ -0,0 +1,239 @@
+package sdkconverter
+
+import (
+	"github.com/jkomoros/sudoku"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+PencilMarkFormat is a grid of every cell's remaining candidates, the way
+many forums and tools (for example, HoDoKu's "PM grid") exchange positions
+in the middle of a solve. Each row is on its own line, with each cell
+separated by whitespace. Each cell is:
+
+* Either a '*' followed by the digit the cell is filled with, or the digits
+that are still possible in the cell. A '-' means the cell has no candidates
+left. DataString always marks filled cells with '*', and if any cell is
+marked, a single digit is always an unfilled cell with only one candidate
+left (a pending naked single). Files like HoDoKu's don't mark filled cells,
+so if no cell is marked, a single digit is treated as filled unless one of
+its neighbors (see Cell.Neighbors) still has the digit as a candidate,
+since a filled number would have ruled it out.
+
+* An optional list of 1 or more marks, contained in "(" and ")" and
+separated by ",", as in doku.
+
+'|' characters between cells and border lines made up only of periods,
+colons, apostrophes, '+', '-', and '|' (like HoDoKu's) are ignored. When
+loading, every number that isn't a candidate in an unfilled cell is
+excluded, so the grid's possibilities match the file. Loaded numbers are not
+locked.
+*/
+const PencilMarkFormat Format = "pm"
+
+const _PENCIL_MARK_CELL_RE = `^(-|\*[1-9]|[1-9]+)(\(([1-9],)*[1-9]\))?$`
+
+var pencilMarkCellRE = regexp.MustCompile(_PENCIL_MARK_CELL_RE)
+
+type pencilMarkConverter struct {
+}
+
+//pencilMarkCells returns the DIM * DIM cells of puzzle, row by row, or nil
+//if it isn't valid.
+func pencilMarkCells(puzzle string) []string {
+	var result []string
+
+	rows := 0
+
+	for _, line := range strings.Split(puzzle, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.Trim(line, ".:'+-|") == "" {
+			//Border
+			continue
+		}
+		cells := strings.Fields(strings.Replace(line, "|", " ", -1))
+		if len(cells) != sudoku.DIM {
+			return nil
+		}
+		for _, cell := range cells {
+			if !pencilMarkCellRE.MatchString(cell) {
+				return nil
+			}
+		}
+		result = append(result, cells...)
+		rows++
+	}
+
+	if rows != sudoku.DIM {
+		return nil
+	}
+
+	return result
+}
+
+func (c *pencilMarkConverter) Valid(puzzle string) bool {
+	return pencilMarkCells(puzzle) != nil
+}
+
+func (c *pencilMarkConverter) Load(grid sudoku.MutableGrid, puzzle string) bool {
+	cells := pencilMarkCells(puzzle)
+
+	if cells == nil {
+		return false
+	}
+
+	candidates := make([]sudoku.IntSlice, len(cells))
+	filled := make([]bool, len(cells))
+	infos := make([]cellInfo, len(cells))
+
+	for i, data := range cells {
+		inMarkSection := false
+		for _, ch := range data {
+			switch {
+			case ch == '*':
+				filled[i] = true
+			case ch == '(':
+				inMarkSection = true
+			case ch >= '1' && ch <= '9':
+				num := int(ch - '0')
+				if inMarkSection {
+					infos[i].marks = append(infos[i].marks, num)
+				} else {
+					candidates[i] = append(candidates[i], num)
+				}
+			}
+		}
+	}
+
+	//If any cell is marked as filled, the file marks all of them, so single
+	//digits are never filled.
+	marksFilled := false
+	for _, isFilled := range filled {
+		if isFilled {
+			marksFilled = true
+		}
+	}
+
+	//Fill in every number first, so that the excludes below are based on
+	//the final state of the grid.
+	for i, info := range infos {
+		cell := grid.MutableCell(i/sudoku.DIM, i%sudoku.DIM)
+		if filled[i] {
+			info.number = candidates[i][0]
+		} else if !marksFilled && len(candidates[i]) == 1 {
+			//A single digit is filled, unless a neighbor still has it as a
+			//candidate.
+			info.number = candidates[i][0]
+			for _, neighbor := range cell.Neighbors() {
+				j := neighbor.Row()*sudoku.DIM + neighbor.Col()
+				if !filled[j] && len(candidates[j].Intersection(sudoku.IntSlice{info.number})) != 0 {
+					info.number = 0
+					break
+				}
+			}
+		}
+		info.fillCell(cell)
+	}
+
+	for i, cellCandidates := range candidates {
+		cell := grid.MutableCell(i/sudoku.DIM, i%sudoku.DIM)
+		cell.ResetExcludes()
+		if cell.Number() != 0 {
+			continue
+		}
+		possible := make(map[int]bool)
+		for _, num := range cellCandidates {
+			possible[num] = true
+		}
+		for num := 1; num <= sudoku.DIM; num++ {
+			if !possible[num] {
+				cell.SetExcluded(num, true)
+			}
+		}
+	}
+
+	return true
+}
+
+func (c *pencilMarkConverter) DataString(grid sudoku.Grid) string {
+
+	cells := make([]string, sudoku.DIM*sudoku.DIM)
+	widths := make([]int, sudoku.DIM)
+
+	for i, cell := range grid.Cells() {
+		data := ""
+		if cell.Number() != 0 {
+			data = "*" + strconv.Itoa(cell.Number())
+		} else {
+			for _, num := range cell.Possibilities() {
+				data += strconv.Itoa(num)
+			}
+			if data == "" {
+				data = "-"
+			}
+		}
+		if marks := cell.Marks(); len(marks) != 0 {
+			var stringMarkList []string
+			for _, mark := range marks {
+				stringMarkList = append(stringMarkList, strconv.Itoa(mark))
+			}
+			data += "(" + strings.Join(stringMarkList, ",") + ")"
+		}
+		cells[i] = data
+		if len(data) > widths[i%sudoku.DIM] {
+			widths[i%sudoku.DIM] = len(data)
+		}
+	}
+
+	//How wide each stack is, not including the space on either side.
+	stackWidths := make([]int, sudoku.DIM/sudoku.BLOCK_DIM)
+	for c, width := range widths {
+		stackWidths[c/sudoku.BLOCK_DIM] += width
+		if c%sudoku.BLOCK_DIM != 0 {
+			stackWidths[c/sudoku.BLOCK_DIM] += 2
+		}
+	}
+
+	border := func(corner string, middle string) string {
+		result := corner
+		for i, width := range stackWidths {
+			result += strings.Repeat("-", width+2)
+			if i == len(stackWidths)-1 {
+				result += corner
+			} else {
+				result += middle
+			}
+		}
+		return result
+	}
+
+	var lines []string
+
+	lines = append(lines, border(".", "."))
+
+	for r := 0; r < sudoku.DIM; r++ {
+		if r != 0 && r%sudoku.BLOCK_DIM == 0 {
+			lines = append(lines, border(":", "+"))
+		}
+		line := "|"
+		for c := 0; c < sudoku.DIM; c++ {
+			data := cells[r*sudoku.DIM+c]
+			line += " " + data + strings.Repeat(" ", widths[c]-len(data))
+			if c%sudoku.BLOCK_DIM == sudoku.BLOCK_DIM-1 {
+				line += " |"
+			} else {
+				line += " "
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, border("'", "'"))
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,13 @@
+.-----------------------.----------------------.--------------------.
+| 1469    5      7      | 269    1269    1269  | 3      8     124   |
+| 1489    2      1489   | 789    15789   1359  | 157    6     1457  |
+| 1689    689    3      | 26789  4       12569 | 1257   1279  1257  |
+:-----------------------+----------------------+--------------------:
+| 479     479    5      | 1      29      8     | 6      2347  2347  |
+| 3       8      12468  | 5      26      7     | 128    124   9     |
+| 146789  46789  124689 | 2469   3       269   | 12578  1247  12457 |
+:-----------------------+----------------------+--------------------:
+| 5678    678    68     | 3      125678  4     | 9      127   1267  |
+| 6789    1      689    | 26789  26789   269   | 4      5     2367  |
+| 2       34679  469    | 679    15679   1569  | 17     137   8     |
+'-----------------------'----------------------'--------------------'
\ No newline at end of file
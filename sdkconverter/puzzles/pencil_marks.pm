@@ -0,0 +1,13 @@
+.--------------------------.----------------------.--------------------.
+| *9(2,3,4)  *5     *7     | 26     126     126   | *3     *8    124   |
+| 148        *2     148    | 789    1789    1359  | 157    *6    1457  |
+| 168(1,2)   68     *3     | 26789  *4      12569 | 1257   1279  1257  |
+:--------------------------+----------------------+--------------------:
+| 47         479    *5     | *1     29      *8    | *6     2347  2347  |
+| *3         468    12468  | *5     26      *7    | 128    124   *9    |
+| 14678      46789  124689 | 2469   *3      269   | 12578  1247  12457 |
+:--------------------------+----------------------+--------------------:
+| 5678       678    68     | *3     125678  *4    | *9     127   1267  |
+| 678        *1     689    | 26789  26789   269   | *4     *5    2367  |
+| *2         34679  469    | 679    15679   1569  | 17     137   *8    |
+'--------------------------'----------------------'--------------------'
\ No newline at end of file
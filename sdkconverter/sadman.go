@@ -0,0 +1,340 @@
+package sdkconverter
+
+import (
+	"github.com/jkomoros/sudoku"
+	"strconv"
+	"strings"
+)
+
+/*
+SadManFormat is the format used by SadMan Software's sudoku programs, and the
+format of many large puzzle collections. A file starts with optional
+metadata lines, each a '#', a single letter saying what kind of metadata it
+is, and the value:
+
+	#A Author
+	#D Description
+	#C Comment
+	#B Date published
+	#S Source
+	#L Level
+	#U Source URL
+
+The metadata is followed by sections, each a header line in square brackets
+followed by the section's rows:
+
+* [Puzzle] is DIM rows of DIM characters, each a number 1-9 for a clue or a
+'.' or '0' for an unfilled cell. Clues are loaded as locked cells. This
+section is required.
+
+* [State] is the same as [Puzzle], but contains the numbers currently filled
+in, including the ones a user has filled in. Cells filled in here but not in
+[Puzzle] are loaded as unlocked numbers.
+
+* [PencilMarks] is DIM rows, each with DIM cells separated by ','. Each cell
+is the digits marked in it, or nothing if it has no marks.
+
+Older SadMan files with no sections are just DIM rows of DIM characters
+(after any metadata), which are treated as the [Puzzle] section. Those
+without metadata are also valid sdk files.
+*/
+const SadManFormat Format = "sadman"
+
+const _SADMAN_PUZZLE_SECTION = "[Puzzle]"
+const _SADMAN_STATE_SECTION = "[State]"
+const _SADMAN_PENCIL_MARKS_SECTION = "[PencilMarks]"
+
+//SadManMetadata is the optional information about a puzzle stored at the
+//top of a file in SadManFormat.
+type SadManMetadata struct {
+	Author      string
+	Description string
+	Comment     string
+	Date        string
+	Source      string
+	Level       string
+	URL         string
+}
+
+type sadManConverter struct {
+}
+
+//sadManFile is a file in SadManFormat split into its parts.
+type sadManFile struct {
+	metadata SadManMetadata
+	//The rows of each section, keyed by the section's header.
+	sections map[string][]string
+}
+
+//field returns a pointer to the field in the metadata that the given
+//metadata letter is stored in, or nil if the letter isn't known.
+func (self *SadManMetadata) field(letter byte) *string {
+	switch letter {
+	case 'A':
+		return &self.Author
+	case 'D':
+		return &self.Description
+	case 'C':
+		return &self.Comment
+	case 'B':
+		return &self.Date
+	case 'S':
+		return &self.Source
+	case 'L':
+		return &self.Level
+	case 'U':
+		return &self.URL
+	}
+	return nil
+}
+
+//parseSadMan splits puzzle into its parts, returning nil if it isn't a valid
+//file in SadManFormat.
+func parseSadMan(puzzle string) *sadManFile {
+	result := &sadManFile{
+		sections: make(map[string][]string),
+	}
+
+	section := ""
+
+	for _, line := range strings.Split(puzzle, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if section != "" || len(line) < 2 {
+				//Metadata has to come before the sections.
+				return nil
+			}
+			//Unknown kinds of metadata are ignored.
+			if field := result.metadata.field(line[1]); field != nil {
+				*field = strings.TrimSpace(line[2:])
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line
+			if _, ok := result.sections[section]; ok {
+				//Repeated section
+				return nil
+			}
+			result.sections[section] = nil
+			continue
+		}
+		if section == "" {
+			//Rows before any section header are an implicit [Puzzle]
+			//section, as in older files.
+			section = _SADMAN_PUZZLE_SECTION
+			result.sections[section] = nil
+		}
+		result.sections[section] = append(result.sections[section], line)
+	}
+
+	if !validSadManNumbers(result.sections[_SADMAN_PUZZLE_SECTION]) {
+		return nil
+	}
+
+	if state, ok := result.sections[_SADMAN_STATE_SECTION]; ok && !validSadManNumbers(state) {
+		return nil
+	}
+
+	if marks, ok := result.sections[_SADMAN_PENCIL_MARKS_SECTION]; ok && !validSadManMarks(marks) {
+		return nil
+	}
+
+	return result
+}
+
+//validSadManNumbers returns true if rows is a valid [Puzzle] or [State]
+//section.
+func validSadManNumbers(rows []string) bool {
+	if len(rows) != sudoku.DIM {
+		return false
+	}
+	for _, row := range rows {
+		if len(row) != sudoku.DIM {
+			return false
+		}
+		for _, ch := range row {
+			if ch != '.' && (ch < '0' || ch > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+//validSadManMarks returns true if rows is a valid [PencilMarks] section.
+func validSadManMarks(rows []string) bool {
+	if len(rows) != sudoku.DIM {
+		return false
+	}
+	for _, row := range rows {
+		cells := strings.Split(row, ",")
+		if len(cells) != sudoku.DIM {
+			return false
+		}
+		for _, cell := range cells {
+			for _, ch := range strings.TrimSpace(cell) {
+				if ch < '1' || ch > '9' {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+//sadManNumber returns the number stored in a [Puzzle] or [State] section for
+//the given cell.
+func sadManNumber(rows []string, r int, c int) int {
+	if rows == nil {
+		return 0
+	}
+	number, err := strconv.Atoi(string(rows[r][c]))
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+func (c *sadManConverter) Valid(puzzle string) bool {
+	return parseSadMan(puzzle) != nil
+}
+
+func (c *sadManConverter) Load(grid sudoku.MutableGrid, puzzle string) bool {
+	file := parseSadMan(puzzle)
+
+	if file == nil {
+		return false
+	}
+
+	clues := file.sections[_SADMAN_PUZZLE_SECTION]
+	state := file.sections[_SADMAN_STATE_SECTION]
+	marks := file.sections[_SADMAN_PENCIL_MARKS_SECTION]
+
+	for r := 0; r < sudoku.DIM; r++ {
+		var markCells []string
+		if marks != nil {
+			markCells = strings.Split(marks[r], ",")
+		}
+		for c := 0; c < sudoku.DIM; c++ {
+			info := cellInfo{}
+			if clue := sadManNumber(clues, r, c); clue != 0 {
+				info.number = clue
+				info.locked = true
+			} else {
+				info.number = sadManNumber(state, r, c)
+			}
+			if markCells != nil {
+				for _, ch := range strings.TrimSpace(markCells[c]) {
+					info.marks = append(info.marks, int(ch-'0'))
+				}
+			}
+			info.fillCell(grid.MutableCell(r, c))
+		}
+	}
+
+	return true
+}
+
+func (c *sadManConverter) DataString(grid sudoku.Grid) string {
+	return SadManDataString(grid, nil)
+}
+
+//LoadSadManMetadata returns the metadata stored in puzzle, which should be
+//in SadManFormat. Returns nil if puzzle isn't valid.
+func LoadSadManMetadata(puzzle string) *SadManMetadata {
+	file := parseSadMan(puzzle)
+	if file == nil {
+		return nil
+	}
+	return &file.metadata
+}
+
+//SadManDataString returns the grid in SadManFormat, with the given metadata
+//(which may be nil) at the top. Locked cells are the clues in [Puzzle]; if
+//no cells are locked, every filled cell is treated as a clue. The [State]
+//and [PencilMarks] sections are only included if there are unlocked filled
+//cells or marks to put in them.
+func SadManDataString(grid sudoku.Grid, metadata *SadManMetadata) string {
+
+	var lines []string
+
+	if metadata != nil {
+		for _, letter := range []byte("ADCBSLU") {
+			if value := *metadata.field(letter); value != "" {
+				lines = append(lines, "#"+string(letter)+value)
+			}
+		}
+	}
+
+	anyLocked := false
+	needState := false
+	needMarks := false
+
+	for _, cell := range grid.Cells() {
+		if cell.Locked() {
+			anyLocked = true
+		}
+		if len(cell.Marks()) > 0 {
+			needMarks = true
+		}
+	}
+
+	isClue := func(cell sudoku.Cell) bool {
+		if anyLocked {
+			return cell.Locked()
+		}
+		return cell.Number() != 0
+	}
+
+	for _, cell := range grid.Cells() {
+		if cell.Number() != 0 && !isClue(cell) {
+			needState = true
+		}
+	}
+
+	numberRows := func(includeUser bool) []string {
+		var result []string
+		for r := 0; r < sudoku.DIM; r++ {
+			row := ""
+			for c := 0; c < sudoku.DIM; c++ {
+				cell := grid.Cell(r, c)
+				if cell.Number() != 0 && (includeUser || isClue(cell)) {
+					row += strconv.Itoa(cell.Number())
+				} else {
+					row += "."
+				}
+			}
+			result = append(result, row)
+		}
+		return result
+	}
+
+	lines = append(lines, _SADMAN_PUZZLE_SECTION)
+	lines = append(lines, numberRows(false)...)
+
+	if needState {
+		lines = append(lines, _SADMAN_STATE_SECTION)
+		lines = append(lines, numberRows(true)...)
+	}
+
+	if needMarks {
+		lines = append(lines, _SADMAN_PENCIL_MARKS_SECTION)
+		for r := 0; r < sudoku.DIM; r++ {
+			var cells []string
+			for c := 0; c < sudoku.DIM; c++ {
+				marks := ""
+				for _, mark := range grid.Cell(r, c).Marks() {
+					marks += strconv.Itoa(mark)
+				}
+				cells = append(cells, marks)
+			}
+			lines = append(lines, strings.Join(cells, ","))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
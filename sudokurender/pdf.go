@@ -0,0 +1,360 @@
+package sudokurender
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"github.com/jkomoros/sudoku"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//The size of a page (US Letter) and its margins, in points.
+const _PDF_PAGE_WIDTH = 612.0
+const _PDF_PAGE_HEIGHT = 792.0
+const _PDF_MARGIN = 48.0
+
+//The space between puzzles on a page.
+const _PDF_GUTTER = 18.0
+
+//The smallest a grid can be drawn, so that its numbers are still legible.
+const _PDF_MIN_GRID_SIZE = 72.0
+
+const _PDF_HEADER_SIZE = 18.0
+const _PDF_TITLE_SIZE = 12.0
+const _PDF_LABEL_SIZE = 9.0
+const _PDF_PAGE_NUMBER_SIZE = 10.0
+
+//The fonts every page can use. Both are among the fonts every PDF reader
+//has, so they don't need to be embedded.
+const _PDF_FONT = "/F1"
+const _PDF_BOLD_FONT = "/F2"
+
+//In Helvetica (and Helvetica-Bold) every digit is this wide, as a
+//proportion of the font size. Roughly how wide other characters are on
+//average.
+const _PDF_DIGIT_WIDTH = 0.556
+
+//In Helvetica, how far below the center of a digit its baseline is, as a
+//proportion of the font size.
+const _PDF_DIGIT_BASELINE = 0.36
+
+//DEFAULT_PUZZLES_PER_PAGE is how many puzzles are on each page of a booklet
+//if BookletOptions don't say.
+const DEFAULT_PUZZLES_PER_PAGE = 4
+
+//DEFAULT_ANSWERS_PER_PAGE is how many solutions are on each page of a
+//booklet's answer key if BookletOptions don't say.
+const DEFAULT_ANSWERS_PER_PAGE = 9
+
+//BookletPuzzle is one of the puzzles in a booklet.
+type BookletPuzzle struct {
+	Grid sudoku.Grid
+	//Title is printed above the puzzle, and above its solution in the
+	//answer key.
+	Title string
+	//Label is printed in smaller type after the title, for example the
+	//puzzle's difficulty.
+	Label string
+	//Solution is printed in the answer key. If it's nil, the puzzle is left
+	//out of the answer key.
+	Solution sudoku.Grid
+}
+
+//BookletOptions configures how a booklet is laid out. Passing nil where
+//BookletOptions are expected will use reasonable defaults.
+type BookletOptions struct {
+	//Title is printed at the top of every page, if provided.
+	Title string
+	//PuzzlesPerPage defaults to DEFAULT_PUZZLES_PER_PAGE. WriteBooklet
+	//returns an error if there are too many to fit legibly on a page.
+	PuzzlesPerPage int
+	//AnswersPerPage defaults to DEFAULT_ANSWERS_PER_PAGE, and has the same
+	//limit as PuzzlesPerPage.
+	AnswersPerPage int
+	//Candidates is whether unfilled cells without marks should show every
+	//number that is still possible in them, as in Options.
+	Candidates bool
+}
+
+//pdfCanvas draws onto one page of a PDF. Like every canvas it takes
+//coordinates from the top left, offset by origin, although PDF's start at
+//the bottom left.
+type pdfCanvas struct {
+	content bytes.Buffer
+	originX float64
+	originY float64
+}
+
+//pdfNumber formats a number for a PDF content stream.
+func pdfNumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+//pdfColor returns the operands for a color operator.
+func pdfColor(c color.RGBA) string {
+	return pdfNumber(float64(c.R)/255) + " " + pdfNumber(float64(c.G)/255) + " " + pdfNumber(float64(c.B)/255)
+}
+
+//pdfString returns s as a PDF string literal. Characters other than
+//printable ASCII are replaced with '?'.
+func pdfString(s string) string {
+	result := "("
+	for _, ch := range s {
+		switch {
+		case ch == '(' || ch == ')' || ch == '\\':
+			result += "\\" + string(ch)
+		case ch < ' ' || ch > '~':
+			result += "?"
+		default:
+			result += string(ch)
+		}
+	}
+	return result + ")"
+}
+
+func (self *pdfCanvas) point(x, y float64) string {
+	return pdfNumber(self.originX+x) + " " + pdfNumber(_PDF_PAGE_HEIGHT-(self.originY+y))
+}
+
+func (self *pdfCanvas) rect(x, y, width, height float64, fill color.RGBA) {
+	fmt.Fprintf(&self.content, "%s rg %s %s %s re f\n", pdfColor(fill), self.point(x, y+height), pdfNumber(width), pdfNumber(height))
+}
+
+func (self *pdfCanvas) line(x1, y1, x2, y2, width float64, stroke color.RGBA) {
+	fmt.Fprintf(&self.content, "%s RG %s w %s m %s l S\n", pdfColor(stroke), pdfNumber(width), self.point(x1, y1), self.point(x2, y2))
+}
+
+func (self *pdfCanvas) text(x, y, size float64, s string, fill color.RGBA, bold bool) {
+	width := float64(len(s)) * _PDF_DIGIT_WIDTH * size
+	self.label(x-width/2, y+_PDF_DIGIT_BASELINE*size, size, s, fill, bold)
+}
+
+//label draws s with its baseline starting at x, y.
+func (self *pdfCanvas) label(x, y, size float64, s string, fill color.RGBA, bold bool) {
+	font := _PDF_FONT
+	if bold {
+		font = _PDF_BOLD_FONT
+	}
+	fmt.Fprintf(&self.content, "BT %s %s Tf %s rg %s Td %s Tj ET\n", font, pdfNumber(size), pdfColor(fill), self.point(x, y), pdfString(s))
+}
+
+//pdfGridLayout returns the grid arrangement and spacing for perPage grids on a
+//page, in the area below top.
+func pdfGridLayout(perPage int, top float64) (cols, rows int, slotWidth, slotHeight float64) {
+	cols = int(math.Round(math.Sqrt(float64(perPage))))
+	if cols < 1 {
+		cols = 1
+	}
+	rows = (perPage + cols - 1) / cols
+	slotWidth = (_PDF_PAGE_WIDTH - 2*_PDF_MARGIN) / float64(cols)
+	slotHeight = (_PDF_PAGE_HEIGHT - _PDF_MARGIN - top) / float64(rows)
+	return
+}
+
+//pdfGridSize returns how big each of perPage grids on a page with the given
+//heading is, including its border.
+func pdfGridSize(perPage int, heading string) float64 {
+	_, _, slotWidth, slotHeight := pdfGridLayout(perPage, pdfContentTop(heading))
+	return math.Min(slotWidth, slotHeight-_PDF_TITLE_SIZE*1.5) - _PDF_GUTTER
+}
+
+//pdfContentTop returns where the area for grids starts on a page with the
+//given heading.
+func pdfContentTop(heading string) float64 {
+	if heading == "" {
+		return _PDF_MARGIN
+	}
+	return _PDF_MARGIN + _PDF_HEADER_SIZE + _PDF_GUTTER
+}
+
+//drawPDFPage draws the puzzles onto a new page. If answers is true, the
+//puzzles' solutions are drawn instead.
+func drawPDFPage(puzzles []*BookletPuzzle, perPage int, heading string, pageNumber int, answers bool, options *BookletOptions) *pdfCanvas {
+	page := &pdfCanvas{}
+
+	top := pdfContentTop(heading)
+
+	if heading != "" {
+		page.label(_PDF_MARGIN, _PDF_MARGIN+_PDF_HEADER_SIZE, _PDF_HEADER_SIZE, heading, givenColor, true)
+	}
+
+	number := strconv.Itoa(pageNumber)
+	page.text(_PDF_PAGE_WIDTH/2, _PDF_PAGE_HEIGHT-_PDF_MARGIN/2, _PDF_PAGE_NUMBER_SIZE, number, givenColor, false)
+
+	cols, _, slotWidth, slotHeight := pdfGridLayout(perPage, top)
+
+	titleHeight := _PDF_TITLE_SIZE * 1.5
+
+	layout := newLayout(&Options{
+		//Make the whole grid, including its border, fit.
+		CellSize:   pdfGridSize(perPage, heading) / (sudoku.DIM + 1.0/16),
+		Candidates: options.Candidates && !answers,
+	})
+
+	for i, puzzle := range puzzles {
+		slotX := _PDF_MARGIN + float64(i%cols)*slotWidth
+		slotY := top + float64(i/cols)*slotHeight
+
+		//Titles are drawn relative to the page, and grids relative to
+		//their top left corner.
+		page.originX = 0
+		page.originY = 0
+
+		//Center the grid in its slot.
+		gridX := slotX + (slotWidth-layout.size)/2
+		gridY := slotY + titleHeight
+
+		if puzzle.Title != "" {
+			page.label(gridX, gridY-_PDF_TITLE_SIZE/2, _PDF_TITLE_SIZE, puzzle.Title, givenColor, true)
+		}
+		if puzzle.Label != "" {
+			titleWidth := float64(len(puzzle.Title)+1) * _PDF_DIGIT_WIDTH * _PDF_TITLE_SIZE
+			page.label(gridX+titleWidth, gridY-_PDF_TITLE_SIZE/2, _PDF_LABEL_SIZE, puzzle.Label, smallNumberColor, false)
+		}
+
+		page.originX = gridX
+		page.originY = gridY
+
+		grid := puzzle.Grid
+		if answers {
+			grid = puzzle.Solution
+		}
+
+		layout.draw(page, grid, nil)
+	}
+
+	return page
+}
+
+//writePDF writes a PDF document with the given pages to w.
+func writePDF(w io.Writer, pages []*pdfCanvas) error {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("%PDF-1.4\n")
+
+	var offsets []int
+
+	//Objects are numbered from 1 in the order they're written.
+	object := func(body string) {
+		offsets = append(offsets, buffer.Len())
+		fmt.Fprintf(&buffer, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	//The catalog, the page tree, and the two fonts come first, then each
+	//page followed by its contents.
+	const firstPageObject = 5
+
+	var kids []string
+	for i := range pages {
+		kids = append(kids, strconv.Itoa(firstPageObject+2*i)+" 0 R")
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		var content bytes.Buffer
+		compressor := zlib.NewWriter(&content)
+		if _, err := compressor.Write(page.content.Bytes()); err != nil {
+			return err
+		}
+		if err := compressor.Close(); err != nil {
+			return err
+		}
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s 3 0 R %s 4 0 R >> >> /Contents %d 0 R >>",
+			pdfNumber(_PDF_PAGE_WIDTH), pdfNumber(_PDF_PAGE_HEIGHT), _PDF_FONT, _PDF_BOLD_FONT, firstPageObject+2*i+1))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xrefOffset := buffer.Len()
+
+	fmt.Fprintf(&buffer, "xref\n0 %d\n", len(offsets)+1)
+	buffer.WriteString("0000000000 65535 f \n")
+	for _, offset := range offsets {
+		fmt.Fprintf(&buffer, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&buffer, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	_, err := buffer.WriteTo(w)
+	return err
+}
+
+//WriteBooklet writes a printable PDF booklet of the puzzles to w: the
+//puzzles, several to a page, followed by an answer key with the solutions
+//of the puzzles that have them.
+func WriteBooklet(w io.Writer, puzzles []*BookletPuzzle, options *BookletOptions) error {
+
+	if len(puzzles) == 0 {
+		return errors.New("No puzzles for booklet")
+	}
+
+	for _, puzzle := range puzzles {
+		if puzzle == nil || puzzle.Grid == nil {
+			return errors.New("Booklet puzzle has no grid")
+		}
+	}
+
+	if options == nil {
+		options = &BookletOptions{}
+	}
+
+	puzzlesPerPage := options.PuzzlesPerPage
+	if puzzlesPerPage <= 0 {
+		puzzlesPerPage = DEFAULT_PUZZLES_PER_PAGE
+	}
+
+	answersPerPage := options.AnswersPerPage
+	if answersPerPage <= 0 {
+		answersPerPage = DEFAULT_ANSWERS_PER_PAGE
+	}
+
+	heading := "Answers"
+	if options.Title != "" {
+		heading = options.Title + ": " + heading
+	}
+
+	if pdfGridSize(puzzlesPerPage, options.Title) < _PDF_MIN_GRID_SIZE {
+		return errors.New("Too many puzzles per page to fit: " + strconv.Itoa(puzzlesPerPage))
+	}
+
+	if pdfGridSize(answersPerPage, heading) < _PDF_MIN_GRID_SIZE {
+		return errors.New("Too many answers per page to fit: " + strconv.Itoa(answersPerPage))
+	}
+
+	var pages []*pdfCanvas
+
+	for start := 0; start < len(puzzles); start += puzzlesPerPage {
+		end := start + puzzlesPerPage
+		if end > len(puzzles) {
+			end = len(puzzles)
+		}
+		pages = append(pages, drawPDFPage(puzzles[start:end], puzzlesPerPage, options.Title, len(pages)+1, false, options))
+	}
+
+	var answers []*BookletPuzzle
+
+	for _, puzzle := range puzzles {
+		if puzzle.Solution != nil {
+			answers = append(answers, puzzle)
+		}
+	}
+
+	for start := 0; start < len(answers); start += answersPerPage {
+		end := start + answersPerPage
+		if end > len(answers) {
+			end = len(answers)
+		}
+		pages = append(pages, drawPDFPage(answers[start:end], answersPerPage, heading, len(pages)+1, true, options))
+	}
+
+	return writePDF(w, pages)
+}
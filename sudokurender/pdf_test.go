@@ -0,0 +1,192 @@
+package sudokurender
+
+import (
+	"bytes"
+	"compress/zlib"
+	"github.com/jkomoros/sudoku"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//checkPDF fails the test if data isn't a well formed PDF, and returns the
+//uncompressed contents of each of its pages.
+func checkPDF(t *testing.T, data []byte) []string {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("PDF didn't start and end correctly")
+	}
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if match == nil {
+		t.Fatal("PDF had no startxref")
+	}
+
+	xrefOffset, _ := strconv.Atoi(string(match[1]))
+
+	xref := strings.Split(string(data[xrefOffset:]), "\n")
+
+	if xref[0] != "xref" {
+		t.Fatal("startxref didn't point to xref")
+	}
+
+	numObjects, _ := strconv.Atoi(strings.Fields(xref[1])[1])
+
+	for i := 1; i < numObjects; i++ {
+		offset, _ := strconv.Atoi(xref[2+i][:10])
+		if !bytes.HasPrefix(data[offset:], []byte(strconv.Itoa(i)+" 0 obj\n")) {
+			t.Fatal("Object", i, "wasn't where xref said it was")
+		}
+	}
+
+	var result []string
+
+	streamRE := regexp.MustCompile(`/Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+
+	for _, indexes := range streamRE.FindAllSubmatchIndex(data, -1) {
+		length, _ := strconv.Atoi(string(data[indexes[2]:indexes[3]]))
+		stream := data[indexes[1] : indexes[1]+length]
+		if !bytes.HasPrefix(data[indexes[1]+length:], []byte("\nendstream")) {
+			t.Fatal("Stream had the wrong length")
+		}
+		reader, err := zlib.NewReader(bytes.NewReader(stream))
+		if err != nil {
+			t.Fatal("Couldn't decompress stream:", err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal("Couldn't decompress stream:", err)
+		}
+		result = append(result, string(content))
+	}
+
+	if !bytes.Contains(data, []byte("/Count "+strconv.Itoa(len(result))+" >>")) {
+		t.Error("Page tree had the wrong count for", len(result), "pages")
+	}
+
+	return result
+}
+
+func TestBooklet(t *testing.T) {
+	grid := sudoku.LoadSDK(TEST_GRID)
+	solution := grid.Solutions()[0]
+
+	var puzzles []*BookletPuzzle
+
+	for i := 1; i <= 7; i++ {
+		puzzle := &BookletPuzzle{
+			Grid:  grid,
+			Title: "Puzzle " + strconv.Itoa(i),
+			Label: "Easy (0.4)",
+		}
+		//Leave one out of the answer key.
+		if i != 7 {
+			puzzle.Solution = solution
+		}
+		puzzles = append(puzzles, puzzle)
+	}
+
+	var buffer bytes.Buffer
+
+	if err := WriteBooklet(&buffer, puzzles, &BookletOptions{
+		Title:          "Puzzles (Vol. 1)",
+		PuzzlesPerPage: 2,
+		AnswersPerPage: 4,
+	}); err != nil {
+		t.Fatal("Couldn't write booklet:", err)
+	}
+
+	pages := checkPDF(t, buffer.Bytes())
+
+	if len(pages) != 6 {
+		t.Fatal("Wrong number of pages. Got", len(pages))
+	}
+
+	for i, page := range pages {
+		if !strings.Contains(page, "("+strconv.Itoa(i+1)+") Tj") {
+			t.Error("Page", i, "didn't have its page number")
+		}
+	}
+
+	if !strings.Contains(pages[0], "(Puzzles \\(Vol. 1\\)) Tj") || !strings.Contains(pages[0], "(Puzzle 2) Tj") || strings.Contains(pages[0], "(Puzzle 3) Tj") {
+		t.Error("First page had the wrong titles")
+	}
+
+	if !strings.Contains(pages[0], "(Easy \\(0.4\\)) Tj") {
+		t.Error("First page didn't have labels")
+	}
+
+	if !strings.Contains(pages[4], "(Puzzles \\(Vol. 1\\): Answers) Tj") || !strings.Contains(pages[5], "(Puzzle 6) Tj") || strings.Contains(pages[5], "(Puzzle 7) Tj") {
+		t.Error("Answer key had the wrong puzzles")
+	}
+
+	//Every cell of each solution is drawn, but only the filled cells of the
+	//puzzle.
+	numbers := regexp.MustCompile(`\(\d\) Tj`)
+
+	filled := 0
+	for _, cell := range grid.Cells() {
+		if cell.Number() != 0 {
+			filled++
+		}
+	}
+
+	//Each page also has a page number.
+	if count := len(numbers.FindAllString(pages[0], -1)); count != 2*filled+1 {
+		t.Error("Wrong number of numbers on a puzzle page. Got", count, "expected", 2*filled+1)
+	}
+
+	if count := len(numbers.FindAllString(pages[4], -1)); count != 4*sudoku.DIM*sudoku.DIM+1 {
+		t.Error("Wrong number of numbers on an answer page. Got", count)
+	}
+
+	if err := WriteBooklet(&buffer, nil, nil); err == nil {
+		t.Error("Didn't get an error for a booklet with no puzzles")
+	}
+
+	if err := WriteBooklet(&buffer, []*BookletPuzzle{{Title: "No grid"}}, nil); err == nil {
+		t.Error("Didn't get an error for a puzzle with no grid")
+	}
+
+	//Grids shrink to fit more on a page, but only so far.
+	if err := WriteBooklet(&buffer, puzzles, &BookletOptions{PuzzlesPerPage: 25, AnswersPerPage: 25}); err != nil {
+		t.Error("Couldn't write booklet with 25 puzzles per page:", err)
+	}
+
+	if err := WriteBooklet(&buffer, puzzles, &BookletOptions{PuzzlesPerPage: 100}); err == nil {
+		t.Error("Didn't get an error for too many puzzles per page")
+	}
+
+	if err := WriteBooklet(&buffer, puzzles, &BookletOptions{AnswersPerPage: 100}); err == nil {
+		t.Error("Didn't get an error for too many answers per page")
+	}
+}
+
+func TestPDFGridSize(t *testing.T) {
+	for perPage := 1; perPage <= 25; perPage++ {
+		for _, heading := range []string{"", "Answers"} {
+			cols, rows, slotWidth, slotHeight := pdfGridLayout(perPage, pdfContentTop(heading))
+			size := pdfGridSize(perPage, heading)
+			if size < _PDF_MIN_GRID_SIZE {
+				t.Error("Grids were too small with", perPage, "per page:", size)
+			}
+			if float64(cols)*slotWidth > _PDF_PAGE_WIDTH-2*_PDF_MARGIN+0.001 || size > slotWidth || size > slotHeight {
+				t.Error("Grids didn't fit on the page with", perPage, "per page")
+			}
+			if cols*rows < perPage {
+				t.Error("Not enough slots for", perPage, "per page")
+			}
+		}
+	}
+
+	if size := pdfGridSize(1000, ""); size >= _PDF_MIN_GRID_SIZE {
+		t.Error("Grids with 1000 per page weren't too small:", size)
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	if result := pdfString(`a(b)c\d` + "é"); result != `(a\(b\)c\\d?)` {
+		t.Error("Wrong PDF string. Got", result)
+	}
+}
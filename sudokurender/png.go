@@ -0,0 +1,182 @@
+package sudokurender
+
+import (
+	"github.com/jkomoros/sudoku"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+//The height of a digit, as a proportion of the size of the text, the same
+//as in Helvetica.
+const _PNG_DIGIT_HEIGHT = 0.72
+
+//How thick the strokes of digits are, as a proportion of the size of the
+//text.
+const _PNG_STROKE = 0.085
+const _PNG_BOLD_STROKE = 0.125
+
+//point is a point in a glyph or on a canvas.
+type point struct {
+	x, y float64
+}
+
+//pngGlyphs are the digits, drawn as polylines in a box 4 wide and 6 high,
+//from the top left.
+var pngGlyphs map[rune][][]point
+
+func init() {
+	pngGlyphs = map[rune][][]point{
+		'0': {{{2, 0}, {3.2, 0.4}, {3.8, 1.5}, {4, 3}, {3.8, 4.5}, {3.2, 5.6}, {2, 6}, {0.8, 5.6}, {0.2, 4.5}, {0, 3}, {0.2, 1.5}, {0.8, 0.4}, {2, 0}}},
+		'1': {{{0.9, 1.3}, {2.4, 0}, {2.4, 6}}},
+		'2': {{{0.2, 1.4}, {0.8, 0.4}, {2, 0}, {3.2, 0.4}, {3.8, 1.5}, {3.5, 2.6}, {0, 6}, {4, 6}}},
+		'3': {
+			{{0.2, 0.9}, {1, 0.15}, {2, 0}, {3.2, 0.3}, {3.7, 1.3}, {3.3, 2.4}, {2, 2.9}, {1.3, 2.9}},
+			{{2, 2.9}, {3.4, 3.4}, {3.9, 4.5}, {3.4, 5.6}, {2, 6}, {0.9, 5.8}, {0.1, 5}},
+		},
+		'4': {{{3, 6}, {3, 0}, {0, 4.2}, {4, 4.2}}},
+		'5': {{{3.7, 0}, {0.6, 0}, {0.3, 2.8}, {1.2, 2.3}, {2.2, 2.2}, {3.4, 2.6}, {4, 3.8}, {3.7, 5.2}, {2.7, 5.9}, {1.6, 6}, {0.6, 5.7}, {0, 5}}},
+		'6': {{{3.5, 0.6}, {2.6, 0}, {1.6, 0.1}, {0.7, 0.8}, {0.1, 2.3}, {0, 3.8}, {0.3, 5.2}, {1.1, 5.9}, {2.2, 6}, {3.3, 5.6}, {3.9, 4.5}, {3.8, 3.4}, {3.1, 2.6}, {2.1, 2.3}, {1.1, 2.6}, {0.3, 3.4}, {0, 3.8}}},
+		'7': {{{0, 0}, {4, 0}, {1.4, 6}}},
+		'8': {
+			{{2, 0}, {3.2, 0.3}, {3.6, 1.3}, {3.2, 2.3}, {2, 2.8}, {0.8, 2.3}, {0.4, 1.3}, {0.8, 0.3}, {2, 0}},
+			{{2, 2.8}, {3.4, 3.3}, {4, 4.4}, {3.5, 5.6}, {2, 6}, {0.5, 5.6}, {0, 4.4}, {0.6, 3.3}, {2, 2.8}},
+		},
+	}
+
+	//9 is 6 turned upside down.
+	var nine [][]point
+	for _, stroke := range pngGlyphs['6'] {
+		var turned []point
+		for _, p := range stroke {
+			turned = append(turned, point{4 - p.x, 6 - p.y})
+		}
+		nine = append(nine, turned)
+	}
+	pngGlyphs['9'] = nine
+}
+
+//pngCanvas draws into an image, anti-aliased.
+type pngCanvas struct {
+	image *image.RGBA
+}
+
+//blend draws c over the pixel at x, y, with the given coverage from 0 to 1.
+func (self *pngCanvas) blend(x, y int, c color.RGBA, coverage float64) {
+	if coverage <= 0 || !(image.Point{x, y}).In(self.image.Bounds()) {
+		return
+	}
+	if coverage > 1 {
+		coverage = 1
+	}
+	existing := self.image.RGBAAt(x, y)
+	mix := func(from, to uint8) uint8 {
+		return uint8(math.Round(float64(from) + (float64(to)-float64(from))*coverage))
+	}
+	self.image.SetRGBA(x, y, color.RGBA{mix(existing.R, c.R), mix(existing.G, c.G), mix(existing.B, c.B), 0xff})
+}
+
+//overlap returns how much of the pixel starting at pixel is between start
+//and end.
+func overlap(pixel int, start, end float64) float64 {
+	return math.Max(0, math.Min(float64(pixel+1), end)-math.Max(float64(pixel), start))
+}
+
+func (self *pngCanvas) rect(x, y, width, height float64, fill color.RGBA) {
+	for py := int(math.Floor(y)); py < int(math.Ceil(y+height)); py++ {
+		for px := int(math.Floor(x)); px < int(math.Ceil(x+width)); px++ {
+			self.blend(px, py, fill, overlap(px, x, x+width)*overlap(py, y, y+height))
+		}
+	}
+}
+
+//distanceToSegment returns how far p is from the line segment from a to b.
+func distanceToSegment(p, a, b point) float64 {
+	dx, dy := b.x-a.x, b.y-a.y
+	t := 0.0
+	if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+		t = math.Max(0, math.Min(1, ((p.x-a.x)*dx+(p.y-a.y)*dy)/lengthSquared))
+	}
+	return math.Hypot(p.x-(a.x+t*dx), p.y-(a.y+t*dy))
+}
+
+//stroke draws the polylines as one shape, so that where their segments
+//overlap isn't drawn darker.
+func (self *pngCanvas) stroke(polylines [][]point, width float64, c color.RGBA) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, polyline := range polylines {
+		for _, p := range polyline {
+			minX, minY = math.Min(minX, p.x), math.Min(minY, p.y)
+			maxX, maxY = math.Max(maxX, p.x), math.Max(maxY, p.y)
+		}
+	}
+
+	margin := width/2 + 1
+
+	for py := int(math.Floor(minY - margin)); py <= int(math.Ceil(maxY+margin)); py++ {
+		for px := int(math.Floor(minX - margin)); px <= int(math.Ceil(maxX+margin)); px++ {
+			center := point{float64(px) + 0.5, float64(py) + 0.5}
+			distance := math.Inf(1)
+			for _, polyline := range polylines {
+				for i := 1; i < len(polyline); i++ {
+					distance = math.Min(distance, distanceToSegment(center, polyline[i-1], polyline[i]))
+				}
+			}
+			self.blend(px, py, c, width/2+0.5-distance)
+		}
+	}
+}
+
+func (self *pngCanvas) line(x1, y1, x2, y2, width float64, stroke color.RGBA) {
+	self.stroke([][]point{{{x1, y1}, {x2, y2}}}, width, stroke)
+}
+
+func (self *pngCanvas) text(x, y, size float64, s string, fill color.RGBA, bold bool) {
+	scale := size * _PNG_DIGIT_HEIGHT / 6
+	width := size * _PNG_STROKE
+	if bold {
+		width = size * _PNG_BOLD_STROKE
+	}
+	//Glyphs are 4 wide, with room between them.
+	advance := 5.5 * scale
+	left := x - (advance*float64(len(s))-1.5*scale)/2
+	top := y - 3*scale
+	for i, ch := range s {
+		var polylines [][]point
+		for _, glyphLine := range pngGlyphs[ch] {
+			var polyline []point
+			for _, p := range glyphLine {
+				polyline = append(polyline, point{left + float64(i)*advance + p.x*scale, top + p.y*scale})
+			}
+			polylines = append(polylines, polyline)
+		}
+		if len(polylines) > 0 {
+			self.stroke(polylines, width, fill)
+		}
+	}
+}
+
+//Image returns an image of grid. If step is not nil, it is overlaid on the
+//grid. If options is nil, reasonable defaults will be used. Each unit of
+//CellSize is a pixel.
+func Image(grid sudoku.Grid, step *sudoku.SolveStep, options *Options) *image.RGBA {
+	layout := newLayout(options)
+
+	size := int(math.Ceil(layout.size))
+
+	c := &pngCanvas{
+		image: image.NewRGBA(image.Rect(0, 0, size, size)),
+	}
+
+	layout.draw(c, grid, step)
+
+	return c.image
+}
+
+//WritePNG writes a PNG image of grid to w, as in Image.
+func WritePNG(w io.Writer, grid sudoku.Grid, step *sudoku.SolveStep, options *Options) error {
+	return png.Encode(w, Image(grid, step, options))
+}
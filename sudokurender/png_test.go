@@ -0,0 +1,127 @@
+package sudokurender
+
+import (
+	"bytes"
+	"github.com/jkomoros/sudoku"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+//cellPixels returns how many pixels inside the cell (away from its edges)
+//are exactly c.
+func cellPixels(img *image.RGBA, ref sudoku.CellRef, options *Options, c color.RGBA) int {
+	layout := newLayout(options)
+	x, y := layout.cellOrigin(ref)
+	inset := layout.thickLine
+	result := 0
+	for py := int(y + inset); py < int(y+layout.cellSize-inset); py++ {
+		for px := int(x + inset); px < int(x+layout.cellSize-inset); px++ {
+			if img.RGBAAt(px, py) == c {
+				result++
+			}
+		}
+	}
+	return result
+}
+
+func TestImage(t *testing.T) {
+	grid := sudoku.MutableLoadSDK(TEST_GRID)
+
+	grid.MutableCell(0, 3).SetNumber(7)
+
+	options := &Options{CellSize: 40}
+
+	img := Image(grid, nil, options)
+
+	if img.Bounds().Dx() != 363 || img.Bounds().Dy() != 363 {
+		t.Error("Image was the wrong size:", img.Bounds())
+	}
+
+	if cellPixels(img, sudoku.CellRef{Row: 0, Col: 0}, options, givenColor) == 0 {
+		t.Error("Given wasn't drawn")
+	}
+
+	if cellPixels(img, sudoku.CellRef{Row: 0, Col: 3}, options, userColor) == 0 || cellPixels(img, sudoku.CellRef{Row: 0, Col: 3}, options, givenColor) != 0 {
+		t.Error("User entry wasn't drawn in the right color")
+	}
+
+	//An empty cell without marks is blank.
+	blank := cellPixels(img, sudoku.CellRef{Row: 0, Col: 4}, options, backgroundColor)
+
+	if blank == 0 || blank != cellPixels(img, sudoku.CellRef{Row: 1, Col: 5}, options, backgroundColor) {
+		t.Error("Empty cells weren't blank")
+	}
+
+	//Asking for candidates draws them.
+	img = Image(grid, nil, &Options{CellSize: 40, Candidates: true})
+
+	if cellPixels(img, sudoku.CellRef{Row: 0, Col: 4}, options, backgroundColor) == blank {
+		t.Error("Candidates weren't drawn")
+	}
+
+	//Every pixel of a grid line is drawn.
+	layout := newLayout(options)
+	for i := 0; i < img.Bounds().Dy(); i++ {
+		if img.RGBAAt(int(layout.gridOrigin+3*layout.cellSize), i) != lineColor {
+			t.Fatal("Block line wasn't drawn at", i)
+		}
+	}
+}
+
+func TestImageStep(t *testing.T) {
+	grid := sudoku.LoadSDK(TEST_GRID)
+
+	options := &Options{CellSize: 60}
+
+	cull := &sudoku.SolveStep{
+		Technique:    testTechnique(false),
+		TargetCells:  sudoku.CellRefSlice{{Row: 1, Col: 0}},
+		TargetNums:   sudoku.IntSlice{4},
+		PointerCells: sudoku.CellRefSlice{{Row: 2, Col: 1}},
+		PointerNums:  sudoku.IntSlice{9},
+	}
+
+	img := Image(grid, cull, options)
+
+	if cellPixels(img, sudoku.CellRef{Row: 1, Col: 0}, options, targetCellColor) == 0 {
+		t.Error("Target cell wasn't highlighted")
+	}
+
+	if cellPixels(img, sudoku.CellRef{Row: 1, Col: 0}, options, eliminateNumColor) == 0 {
+		t.Error("Eliminated number wasn't drawn")
+	}
+
+	if cellPixels(img, sudoku.CellRef{Row: 2, Col: 1}, options, pointerCellColor) == 0 || cellPixels(img, sudoku.CellRef{Row: 2, Col: 1}, options, pointerNumColor) == 0 {
+		t.Error("Pointer cell wasn't highlighted")
+	}
+
+	fill := &sudoku.SolveStep{
+		Technique:   testTechnique(true),
+		TargetCells: sudoku.CellRefSlice{{Row: 0, Col: 3}},
+		TargetNums:  sudoku.IntSlice{7},
+	}
+
+	img = Image(grid, fill, options)
+
+	if cellPixels(img, sudoku.CellRef{Row: 0, Col: 3}, options, fillNumColor) == 0 {
+		t.Error("Filled number wasn't drawn")
+	}
+
+	var buffer bytes.Buffer
+
+	if err := WritePNG(&buffer, grid, fill, options); err != nil {
+		t.Fatal("Couldn't write PNG:", err)
+	}
+
+	decoded, err := png.Decode(&buffer)
+
+	if err != nil {
+		t.Fatal("Couldn't decode PNG:", err)
+	}
+
+	if decoded.Bounds() != img.Bounds() {
+		t.Error("PNG was the wrong size:", decoded.Bounds())
+	}
+}
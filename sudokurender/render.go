@@ -0,0 +1,220 @@
+/*
+Package sudokurender draws sudoku grids as images, for printing, sharing, and
+documentation. Like Grid.Diagram, it shows the numbers filled in the grid
+and the numbers still possible in unfilled cells, but it can also overlay a
+SolveStep on the grid to illustrate a hint or one step of a walkthrough.
+
+Locked cells (the givens of a puzzle) are drawn in black, and cells filled
+by a user in blue. Unfilled cells show their marks, if they have any, as
+small numbers; if Options.Candidates is true, unfilled cells without marks
+show every number that is still possible in them.
+
+When a step is overlaid, its PointerCells are shaded blue and its
+TargetCells are shaded yellow. The PointerNums are shown in purple in the
+unfilled pointer cells. If the step fills in a number, that number is drawn
+in green in the target cell; otherwise each of the TargetNums it eliminates
+is shown in the target cells in red, struck out.
+
+WriteSVG draws a single grid as an SVG image, and WritePNG (or Image) as a
+PNG, for places like chat and email that can't show SVGs. WriteBooklet lays
+out many puzzles, and an answer key, in a printable PDF.
+*/
+package sudokurender
+
+import (
+	"github.com/jkomoros/sudoku"
+	"image/color"
+	"strconv"
+)
+
+//Options configures how grids are drawn. Passing nil where Options are
+//expected will use reasonable defaults.
+type Options struct {
+	//CellSize is the width and height of each cell. Defaults to
+	//DEFAULT_CELL_SIZE.
+	CellSize float64
+	//Candidates is whether unfilled cells without marks should show every
+	//number that is still possible in them.
+	Candidates bool
+}
+
+//DEFAULT_CELL_SIZE is the CellSize used if Options don't provide one.
+const DEFAULT_CELL_SIZE = 48.0
+
+//The colors used to draw the parts of a grid.
+var (
+	backgroundColor   = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	lineColor         = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	givenColor        = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	userColor         = color.RGBA{0x1a, 0x55, 0xc4, 0xff}
+	smallNumberColor  = color.RGBA{0x55, 0x55, 0x55, 0xff}
+	pointerCellColor  = color.RGBA{0xcf, 0xe2, 0xff, 0xff}
+	targetCellColor   = color.RGBA{0xff, 0xf0, 0xa8, 0xff}
+	pointerNumColor   = color.RGBA{0x6a, 0x3f, 0xc4, 0xff}
+	fillNumColor      = color.RGBA{0x1f, 0x8a, 0x3b, 0xff}
+	eliminateNumColor = color.RGBA{0xd0, 0x21, 0x21, 0xff}
+)
+
+//canvas is something a grid can be drawn on. Coordinates start at the top
+//left.
+type canvas interface {
+	rect(x, y, width, height float64, fill color.RGBA)
+	line(x1, y1, x2, y2, width float64, stroke color.RGBA)
+	//text draws a string of digits, size high, centered on x, y.
+	text(x, y, size float64, s string, fill color.RGBA, bold bool)
+}
+
+//layout is where each part of a grid is drawn, for a given Options.
+type layout struct {
+	options    Options
+	thinLine   float64
+	thickLine  float64
+	cellSize   float64
+	gridOrigin float64
+	size       float64
+}
+
+//smallNumber is a number drawn small in one of the nine spots in a cell.
+type smallNumber struct {
+	fill   color.RGBA
+	bold   bool
+	struck bool
+}
+
+func newLayout(options *Options) *layout {
+	result := &layout{}
+	if options != nil {
+		result.options = *options
+	}
+	if result.options.CellSize <= 0 {
+		result.options.CellSize = DEFAULT_CELL_SIZE
+	}
+	result.cellSize = result.options.CellSize
+	result.thinLine = result.cellSize / 48
+	result.thickLine = result.cellSize / 16
+	//Leave room for half of the thick border around the outside.
+	result.gridOrigin = result.thickLine / 2
+	result.size = result.cellSize*sudoku.DIM + result.thickLine
+	return result
+}
+
+//Size returns the width and height of a grid drawn with the given options.
+func Size(options *Options) float64 {
+	return newLayout(options).size
+}
+
+//cellOrigin returns the top left corner of the cell.
+func (self *layout) cellOrigin(ref sudoku.CellRef) (x, y float64) {
+	return self.gridOrigin + float64(ref.Col)*self.cellSize, self.gridOrigin + float64(ref.Row)*self.cellSize
+}
+
+//smallNumberCenter returns the center of the spot in the cell where number
+//is drawn small.
+func (self *layout) smallNumberCenter(ref sudoku.CellRef, number int) (x, y float64) {
+	x, y = self.cellOrigin(ref)
+	spot := self.cellSize / sudoku.BLOCK_DIM
+	index := number - 1
+	return x + spot*(float64(index%sudoku.BLOCK_DIM)+0.5), y + spot*(float64(index/sudoku.BLOCK_DIM)+0.5)
+}
+
+//draw draws the grid, with step (which may be nil) overlaid, onto c.
+func (self *layout) draw(c canvas, grid sudoku.Grid, step *sudoku.SolveStep) {
+
+	c.rect(0, 0, self.size, self.size, backgroundColor)
+
+	pointerCells := make(map[sudoku.CellRef]bool)
+	targetCells := make(map[sudoku.CellRef]bool)
+
+	if step != nil {
+		for _, ref := range step.PointerCells {
+			pointerCells[ref] = true
+		}
+		for _, ref := range step.TargetCells {
+			targetCells[ref] = true
+		}
+	}
+
+	isFill := step != nil && step.Technique != nil && step.Technique.IsFill()
+
+	for _, cell := range grid.Cells() {
+		ref := cell.Reference()
+		x, y := self.cellOrigin(ref)
+
+		if targetCells[ref] {
+			c.rect(x, y, self.cellSize, self.cellSize, targetCellColor)
+		} else if pointerCells[ref] {
+			c.rect(x, y, self.cellSize, self.cellSize, pointerCellColor)
+		}
+
+		if cell.Number() != 0 {
+			fill := userColor
+			if cell.Locked() {
+				fill = givenColor
+			}
+			c.text(x+self.cellSize/2, y+self.cellSize/2, self.cellSize*0.65, strconv.Itoa(cell.Number()), fill, cell.Locked())
+			continue
+		}
+
+		if isFill && targetCells[ref] && len(step.TargetNums) > 0 {
+			c.text(x+self.cellSize/2, y+self.cellSize/2, self.cellSize*0.65, strconv.Itoa(step.TargetNums[0]), fillNumColor, true)
+			continue
+		}
+
+		smallNumbers := make(map[int]smallNumber)
+
+		shown := cell.Marks()
+		if len(shown) == 0 && self.options.Candidates {
+			shown = cell.Possibilities()
+		}
+
+		for _, number := range shown {
+			smallNumbers[number] = smallNumber{fill: smallNumberColor}
+		}
+
+		if pointerCells[ref] {
+			for _, number := range step.PointerNums {
+				smallNumbers[number] = smallNumber{fill: pointerNumColor, bold: true}
+			}
+		}
+
+		if !isFill && targetCells[ref] {
+			for _, number := range step.TargetNums {
+				smallNumbers[number] = smallNumber{fill: eliminateNumColor, bold: true, struck: true}
+			}
+		}
+
+		for number := 1; number <= sudoku.DIM; number++ {
+			info, ok := smallNumbers[number]
+			if !ok {
+				continue
+			}
+			numberX, numberY := self.smallNumberCenter(ref, number)
+			numberSize := self.cellSize / sudoku.BLOCK_DIM * 0.8
+			c.text(numberX, numberY, numberSize, strconv.Itoa(number), info.fill, info.bold)
+			if info.struck {
+				halfWidth := numberSize * 0.4
+				c.line(numberX-halfWidth, numberY+halfWidth, numberX+halfWidth, numberY-halfWidth, self.thickLine/2, info.fill)
+			}
+		}
+	}
+
+	//Draw the thin lines first, so the thick ones cover them where they
+	//cross.
+	for _, thick := range []bool{false, true} {
+		for i := 0; i <= sudoku.DIM; i++ {
+			if (i%sudoku.BLOCK_DIM == 0) != thick {
+				continue
+			}
+			width := self.thinLine
+			if thick {
+				width = self.thickLine
+			}
+			offset := self.gridOrigin + float64(i)*self.cellSize
+			//Extend the lines to cover the corners of the border.
+			start := self.gridOrigin - width/2
+			end := self.gridOrigin + self.cellSize*sudoku.DIM + width/2
+			c.line(offset, start, offset, end, width, lineColor)
+			c.line(start, offset, end, offset, width, lineColor)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package sudokurender
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/jkomoros/sudoku"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+)
+
+//svgCanvas draws into an SVG document.
+type svgCanvas struct {
+	buffer bytes.Buffer
+}
+
+//svgNumber formats a coordinate for an SVG attribute. Hundredths of a
+//point are plenty precise.
+func svgNumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func (self *svgCanvas) rect(x, y, width, height float64, fill color.RGBA) {
+	fmt.Fprintf(&self.buffer, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\"/>\n",
+		svgNumber(x), svgNumber(y), svgNumber(width), svgNumber(height), svgColor(fill))
+}
+
+func (self *svgCanvas) line(x1, y1, x2, y2, width float64, stroke color.RGBA) {
+	fmt.Fprintf(&self.buffer, "<line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\" stroke=\"%s\" stroke-width=\"%s\"/>\n",
+		svgNumber(x1), svgNumber(y1), svgNumber(x2), svgNumber(y2), svgColor(stroke), svgNumber(width))
+}
+
+func (self *svgCanvas) text(x, y, size float64, s string, fill color.RGBA, bold bool) {
+	weight := "normal"
+	if bold {
+		weight = "bold"
+	}
+	fmt.Fprintf(&self.buffer, "<text x=\"%s\" y=\"%s\" font-size=\"%s\" font-weight=\"%s\" fill=\"%s\">%s</text>\n",
+		svgNumber(x), svgNumber(y), svgNumber(size), weight, svgColor(fill), s)
+}
+
+//WriteSVG writes an SVG image of grid to w. If step is not nil, it is
+//overlaid on the grid. If options is nil, reasonable defaults will be used.
+func WriteSVG(w io.Writer, grid sudoku.Grid, step *sudoku.SolveStep, options *Options) error {
+	layout := newLayout(options)
+
+	c := &svgCanvas{}
+
+	size := svgNumber(layout.size)
+
+	fmt.Fprintf(&c.buffer, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n", size, size, size, size)
+	//Center every number on the point it's drawn at.
+	c.buffer.WriteString("<g font-family=\"Helvetica, Arial, sans-serif\" text-anchor=\"middle\" dominant-baseline=\"central\">\n")
+
+	layout.draw(c, grid, step)
+
+	c.buffer.WriteString("</g>\n</svg>\n")
+
+	_, err := c.buffer.WriteTo(w)
+	return err
+}
+
+//SVG returns an SVG image of grid, as in WriteSVG.
+func SVG(grid sudoku.Grid, step *sudoku.SolveStep, options *Options) string {
+	var buffer bytes.Buffer
+	WriteSVG(&buffer, grid, step, options)
+	return buffer.String()
+}
@@ -0,0 +1,211 @@
+package sudokurender
+
+import (
+	"encoding/xml"
+	"github.com/jkomoros/sudoku"
+	"io"
+	"strings"
+	"testing"
+)
+
+const TEST_GRID = `6|1|2|.|.|.|4|.|3
+.|3|.|4|9|.|.|7|2
+.|.|7|.|.|.|.|6|5
+.|.|.|.|6|1|.|8|.
+1|.|3|.|4|.|2|.|6
+.|6|.|5|2|.|.|.|.
+.|9|.|.|.|.|5|.|.
+7|2|.|.|8|5|.|3|.
+5|.|1|.|.|.|9|4|7`
+
+//svgElement is an element of a drawn SVG that a test cares about.
+type svgElement struct {
+	name  string
+	attrs map[string]string
+	text  string
+}
+
+//parseSVG returns every element in data, failing the test if it isn't well
+//formed.
+func parseSVG(t *testing.T, data string) []*svgElement {
+	var result []*svgElement
+
+	decoder := xml.NewDecoder(strings.NewReader(data))
+
+	var current *svgElement
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("SVG was not well formed:", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			current = &svgElement{
+				name:  token.Name.Local,
+				attrs: make(map[string]string),
+			}
+			for _, attr := range token.Attr {
+				current.attrs[attr.Name.Local] = attr.Value
+			}
+			result = append(result, current)
+		case xml.CharData:
+			if current != nil {
+				current.text += strings.TrimSpace(string(token))
+			}
+		case xml.EndElement:
+			current = nil
+		}
+	}
+
+	return result
+}
+
+//testTechnique returns a technique that fills numbers if isFill, and culls
+//possibilities otherwise.
+func testTechnique(isFill bool) sudoku.SolveTechnique {
+	for _, technique := range sudoku.Techniques {
+		if technique.IsFill() == isFill {
+			return technique
+		}
+	}
+	return nil
+}
+
+//svgTexts returns the text of every text element with the given fill.
+func svgTexts(elements []*svgElement, fill string) []string {
+	var result []string
+	for _, element := range elements {
+		if element.name == "text" && element.attrs["fill"] == fill {
+			result = append(result, element.text)
+		}
+	}
+	return result
+}
+
+func TestSVG(t *testing.T) {
+	grid := sudoku.MutableLoadSDK(TEST_GRID)
+
+	grid.MutableCell(0, 3).SetNumber(7)
+	grid.MutableCell(0, 4).SetMark(5, true)
+	grid.MutableCell(0, 4).SetMark(8, true)
+
+	elements := parseSVG(t, SVG(grid, nil, nil))
+
+	root := elements[0]
+
+	expectedSize := "435"
+
+	if root.name != "svg" || root.attrs["width"] != expectedSize || root.attrs["height"] != expectedSize {
+		t.Error("Wrong root element:", root.name, root.attrs)
+	}
+
+	if Size(nil) != 435 || Size(&Options{CellSize: 10}) != 90+10.0/16 {
+		t.Error("Wrong size", Size(nil))
+	}
+
+	numGivens := 0
+	for _, cell := range grid.Cells() {
+		if cell.Locked() {
+			numGivens++
+		}
+	}
+
+	if givens := svgTexts(elements, svgColor(givenColor)); len(givens) != numGivens {
+		t.Error("Wrong number of givens drawn. Got", len(givens), "expected", numGivens)
+	}
+
+	if texts := svgTexts(elements, svgColor(userColor)); len(texts) != 1 || texts[0] != "7" {
+		t.Error("User entry wasn't drawn:", texts)
+	}
+
+	if texts := svgTexts(elements, svgColor(smallNumberColor)); strings.Join(texts, "") != "58" {
+		t.Error("Marks weren't drawn:", texts)
+	}
+
+	lines := 0
+	for _, element := range elements {
+		if element.name == "line" {
+			lines++
+		}
+	}
+
+	if lines != (sudoku.DIM+1)*2 {
+		t.Error("Wrong number of grid lines. Got", lines)
+	}
+
+	//With candidates, every unfilled cell shows what's possible in it.
+	elements = parseSVG(t, SVG(grid, nil, &Options{Candidates: true}))
+
+	candidates := 0
+	for _, cell := range grid.Cells() {
+		if cell.Number() == 0 {
+			if len(cell.Marks()) > 0 {
+				candidates += len(cell.Marks())
+			} else {
+				candidates += len(cell.Possibilities())
+			}
+		}
+	}
+
+	if texts := svgTexts(elements, svgColor(smallNumberColor)); len(texts) != candidates {
+		t.Error("Wrong number of candidates drawn. Got", len(texts), "expected", candidates)
+	}
+}
+
+func TestSVGStep(t *testing.T) {
+	grid := sudoku.LoadSDK(TEST_GRID)
+
+	cull := &sudoku.SolveStep{
+		Technique:    testTechnique(false),
+		TargetCells:  sudoku.CellRefSlice{{Row: 1, Col: 0}, {Row: 2, Col: 0}},
+		TargetNums:   sudoku.IntSlice{4},
+		PointerCells: sudoku.CellRefSlice{{Row: 0, Col: 6}, {Row: 2, Col: 1}},
+		PointerNums:  sudoku.IntSlice{9},
+	}
+
+	elements := parseSVG(t, SVG(grid, cull, nil))
+
+	targets := 0
+	pointers := 0
+	struck := 0
+
+	for _, element := range elements {
+		switch {
+		case element.name == "rect" && element.attrs["fill"] == svgColor(targetCellColor):
+			targets++
+		case element.name == "rect" && element.attrs["fill"] == svgColor(pointerCellColor):
+			pointers++
+		case element.name == "line" && element.attrs["stroke"] == svgColor(eliminateNumColor):
+			struck++
+		}
+	}
+
+	if targets != 2 || pointers != 2 {
+		t.Error("Wrong cells highlighted. Got", targets, "targets and", pointers, "pointers")
+	}
+
+	if texts := svgTexts(elements, svgColor(eliminateNumColor)); strings.Join(texts, "") != "44" || struck != 2 {
+		t.Error("Eliminated numbers weren't struck out:", texts, struck)
+	}
+
+	//Pointer numbers are only drawn in the unfilled pointer cell.
+	if texts := svgTexts(elements, svgColor(pointerNumColor)); len(texts) != 1 || texts[0] != "9" {
+		t.Error("Pointer numbers weren't drawn:", texts)
+	}
+
+	fill := &sudoku.SolveStep{
+		Technique:   testTechnique(true),
+		TargetCells: sudoku.CellRefSlice{{Row: 0, Col: 3}},
+		TargetNums:  sudoku.IntSlice{7},
+	}
+
+	elements = parseSVG(t, SVG(grid, fill, nil))
+
+	if texts := svgTexts(elements, svgColor(fillNumColor)); len(texts) != 1 || texts[0] != "7" {
+		t.Error("Filled number wasn't drawn:", texts)
+	}
+}
@@ -0,0 +1,432 @@
+package sudoku
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+)
+
+/*
+	This file transforms grids in ways that keep a valid sudoku valid:
+	rotating, reflecting, transposing, permuting bands, stacks, and the rows
+	and columns within them, and relabeling digits. A transformed puzzle is
+	logically the same puzzle, so it can be served in disguise while reusing
+	everything already known about it (see Canonical).
+
+	Every combination of those transformations can be expressed as an
+	optional transposition, followed by a permutation of rows and of columns,
+	along with a relabeling of digits, which is how GridTransform stores
+	them.
+*/
+
+//GridTransform is a transformation of a grid that keeps a valid sudoku
+//valid. Create one with one of the *Transform functions and combine them
+//with Then. Apply it to a grid with Grid.Transform, and to a solve with
+//SolveDirections.Transform.
+type GridTransform struct {
+	//Whether rows and columns are swapped before being permuted.
+	transpose bool
+	//rows[i] is the row that row i (after transposing) moves to.
+	rows [DIM]int
+	//cols[i] is the column that column i (after transposing) moves to.
+	cols [DIM]int
+	//numbers[i] is the number that i is relabeled to. numbers[0] is always 0.
+	numbers [DIM + 1]int
+}
+
+//IdentityTransform returns a GridTransform that leaves grids the same.
+func IdentityTransform() *GridTransform {
+	result := &GridTransform{}
+	for i := 0; i < DIM; i++ {
+		result.rows[i] = i
+		result.cols[i] = i
+	}
+	for i := 0; i <= DIM; i++ {
+		result.numbers[i] = i
+	}
+	return result
+}
+
+//TransposeTransform returns a GridTransform that swaps rows and columns,
+//mirroring the grid across the diagonal from the top left to the bottom
+//right.
+func TransposeTransform() *GridTransform {
+	result := IdentityTransform()
+	result.transpose = true
+	return result
+}
+
+//reversedTransform returns a GridTransform that reverses the order of the
+//rows and/or columns.
+func reversedTransform(rows bool, cols bool) *GridTransform {
+	result := IdentityTransform()
+	for i := 0; i < DIM; i++ {
+		if rows {
+			result.rows[i] = DIM - i - 1
+		}
+		if cols {
+			result.cols[i] = DIM - i - 1
+		}
+	}
+	return result
+}
+
+//RotateTransform returns a GridTransform that rotates the grid clockwise by
+//the given number of quarter turns. Negative numbers rotate
+//counter-clockwise.
+func RotateTransform(quarterTurns int) *GridTransform {
+	quarterTurns = ((quarterTurns % 4) + 4) % 4
+
+	//A quarter turn clockwise moves (r, c) to (c, DIM - r - 1).
+	quarterTurn := TransposeTransform().Then(reversedTransform(false, true))
+
+	result := IdentityTransform()
+	for i := 0; i < quarterTurns; i++ {
+		result = result.Then(quarterTurn)
+	}
+	return result
+}
+
+//ReflectTransform returns a GridTransform that mirrors the grid the same way
+//Cell.SymmetricalPartner does for the given type of symmetry. Only
+//SYMMETRY_HORIZONTAL, SYMMETRY_VERTICAL, SYMMETRY_DIAGONAL, and
+//SYMMETRY_ANTI_DIAGONAL are reflections; any other type returns an error.
+func ReflectTransform(symmetry SymmetryType) (*GridTransform, error) {
+	switch symmetry {
+	case SYMMETRY_HORIZONTAL:
+		return reversedTransform(true, false), nil
+	case SYMMETRY_VERTICAL:
+		return reversedTransform(false, true), nil
+	case SYMMETRY_DIAGONAL:
+		return TransposeTransform(), nil
+	case SYMMETRY_ANTI_DIAGONAL:
+		return TransposeTransform().Then(reversedTransform(true, true)), nil
+	}
+	return nil, errors.New("Symmetry type is not a reflection")
+}
+
+//validPermutation returns true if permutation contains each number from 0 to
+//length - 1 exactly once.
+func validPermutation(permutation []int, length int) bool {
+	if len(permutation) != length {
+		return false
+	}
+	seen := make(map[int]bool)
+	for _, item := range permutation {
+		if item < 0 || item >= length || seen[item] {
+			return false
+		}
+		seen[item] = true
+	}
+	return true
+}
+
+//PermuteBandsTransform returns a GridTransform that moves each band (a
+//horizontal group of BLOCK_DIM blocks) to a new position: band i moves to
+//permutation[i]. The rows within each band stay in the same order.
+func PermuteBandsTransform(permutation []int) (*GridTransform, error) {
+	if !validPermutation(permutation, BLOCK_DIM) {
+		return nil, errors.New("Band permutation must contain each band exactly once")
+	}
+	result := IdentityTransform()
+	for i := 0; i < DIM; i++ {
+		result.rows[i] = permutation[i/BLOCK_DIM]*BLOCK_DIM + i%BLOCK_DIM
+	}
+	return result, nil
+}
+
+//PermuteStacksTransform returns a GridTransform that moves each stack (a
+//vertical group of BLOCK_DIM blocks) to a new position: stack i moves to
+//permutation[i]. The columns within each stack stay in the same order.
+func PermuteStacksTransform(permutation []int) (*GridTransform, error) {
+	if !validPermutation(permutation, BLOCK_DIM) {
+		return nil, errors.New("Stack permutation must contain each stack exactly once")
+	}
+	result := IdentityTransform()
+	for i := 0; i < DIM; i++ {
+		result.cols[i] = permutation[i/BLOCK_DIM]*BLOCK_DIM + i%BLOCK_DIM
+	}
+	return result, nil
+}
+
+//PermuteRowsTransform returns a GridTransform that reorders the rows within
+//the given band: the row at index i within the band moves to index
+//permutation[i].
+func PermuteRowsTransform(band int, permutation []int) (*GridTransform, error) {
+	if band < 0 || band >= BLOCK_DIM {
+		return nil, errors.New("Invalid band")
+	}
+	if !validPermutation(permutation, BLOCK_DIM) {
+		return nil, errors.New("Row permutation must contain each row in the band exactly once")
+	}
+	result := IdentityTransform()
+	for i := 0; i < BLOCK_DIM; i++ {
+		result.rows[band*BLOCK_DIM+i] = band*BLOCK_DIM + permutation[i]
+	}
+	return result, nil
+}
+
+//PermuteColsTransform returns a GridTransform that reorders the columns
+//within the given stack: the column at index i within the stack moves to
+//index permutation[i].
+func PermuteColsTransform(stack int, permutation []int) (*GridTransform, error) {
+	if stack < 0 || stack >= BLOCK_DIM {
+		return nil, errors.New("Invalid stack")
+	}
+	if !validPermutation(permutation, BLOCK_DIM) {
+		return nil, errors.New("Column permutation must contain each column in the stack exactly once")
+	}
+	result := IdentityTransform()
+	for i := 0; i < BLOCK_DIM; i++ {
+		result.cols[stack*BLOCK_DIM+i] = stack*BLOCK_DIM + permutation[i]
+	}
+	return result, nil
+}
+
+//RelabelTransform returns a GridTransform that changes each number in the
+//grid: the number i + 1 becomes labels[i]. labels must contain each number
+//from 1 to DIM exactly once.
+func RelabelTransform(labels []int) (*GridTransform, error) {
+	zeroBased := make([]int, len(labels))
+	for i, label := range labels {
+		zeroBased[i] = label - 1
+	}
+	if !validPermutation(zeroBased, DIM) {
+		return nil, errors.New("Labels must contain each number exactly once")
+	}
+	result := IdentityTransform()
+	for i, label := range labels {
+		result.numbers[i+1] = label
+	}
+	return result, nil
+}
+
+//RandomTransform returns a GridTransform that randomly transposes, permutes
+//bands, stacks, rows, and columns, and relabels digits. It's a convenient
+//way to disguise a puzzle.
+func RandomTransform() *GridTransform {
+	result := IdentityTransform()
+
+	if rand.Intn(2) == 0 {
+		result = TransposeTransform()
+	}
+
+	bands, _ := PermuteBandsTransform(rand.Perm(BLOCK_DIM))
+	stacks, _ := PermuteStacksTransform(rand.Perm(BLOCK_DIM))
+	result = result.Then(bands).Then(stacks)
+
+	for i := 0; i < BLOCK_DIM; i++ {
+		rows, _ := PermuteRowsTransform(i, rand.Perm(BLOCK_DIM))
+		cols, _ := PermuteColsTransform(i, rand.Perm(BLOCK_DIM))
+		result = result.Then(rows).Then(cols)
+	}
+
+	labels := rand.Perm(DIM)
+	for i := range labels {
+		labels[i]++
+	}
+	relabel, _ := RelabelTransform(labels)
+
+	return result.Then(relabel)
+}
+
+//MapCell returns where the cell at ref moves to under the transform.
+func (self *GridTransform) MapCell(ref CellRef) CellRef {
+	row, col := ref.Row, ref.Col
+	if self.transpose {
+		row, col = col, row
+	}
+	return CellRef{self.rows[row], self.cols[col]}
+}
+
+//MapNumber returns the number that number is relabeled to under the
+//transform. 0 (an unfilled cell) always stays 0.
+func (self *GridTransform) MapNumber(number int) int {
+	if number < 0 || number > DIM {
+		return number
+	}
+	return self.numbers[number]
+}
+
+//fromCellMapping returns the GridTransform that moves cells according to
+//mapping, which must be a valid combination of transformations.
+func fromCellMapping(transpose bool, mapping func(CellRef) CellRef) *GridTransform {
+	result := &GridTransform{transpose: transpose}
+	for i := 0; i < DIM; i++ {
+		if transpose {
+			//(r, c) moves to (rows[c], cols[r])
+			result.rows[i] = mapping(CellRef{0, i}).Row
+			result.cols[i] = mapping(CellRef{i, 0}).Col
+		} else {
+			result.rows[i] = mapping(CellRef{i, 0}).Row
+			result.cols[i] = mapping(CellRef{0, i}).Col
+		}
+	}
+	return result
+}
+
+//Then returns a GridTransform that applies this transform and then other.
+func (self *GridTransform) Then(other *GridTransform) *GridTransform {
+	result := fromCellMapping(self.transpose != other.transpose, func(ref CellRef) CellRef {
+		return other.MapCell(self.MapCell(ref))
+	})
+	for i := 0; i <= DIM; i++ {
+		result.numbers[i] = other.numbers[self.numbers[i]]
+	}
+	return result
+}
+
+//Inverse returns the GridTransform that undoes this one.
+func (self *GridTransform) Inverse() *GridTransform {
+	inverse := make(map[CellRef]CellRef)
+	for r := 0; r < DIM; r++ {
+		for c := 0; c < DIM; c++ {
+			ref := CellRef{r, c}
+			inverse[self.MapCell(ref)] = ref
+		}
+	}
+	result := fromCellMapping(self.transpose, func(ref CellRef) CellRef {
+		return inverse[ref]
+	})
+	for i := 0; i <= DIM; i++ {
+		result.numbers[self.numbers[i]] = i
+	}
+	return result
+}
+
+//mapTechnique returns the technique that does the same thing as technique
+//once the grid has been transformed. Transposing turns rows into columns,
+//so for example Necessary In Row becomes Necessary In Col.
+func (self *GridTransform) mapTechnique(technique SolveTechnique) SolveTechnique {
+	if !self.transpose || technique == nil {
+		return technique
+	}
+	name := technique.Name()
+	var swapped string
+	if strings.HasSuffix(name, " Row") {
+		swapped = strings.TrimSuffix(name, " Row") + " Col"
+	} else if strings.HasSuffix(name, " Col") {
+		swapped = strings.TrimSuffix(name, " Col") + " Row"
+	} else {
+		return technique
+	}
+	if other, ok := techniquesByName[swapped]; ok {
+		return other
+	}
+	return technique
+}
+
+//transformGridImpl is the shared implementation of Grid.Transform.
+func transformGridImpl(grid Grid, transform *GridTransform) MutableGrid {
+	result := NewGrid()
+
+	for _, cell := range grid.Cells() {
+		ref := transform.MapCell(cell.Reference())
+		target := result.MutableCell(ref.Row, ref.Col)
+		target.SetNumber(transform.MapNumber(cell.Number()))
+		for i := 1; i <= DIM; i++ {
+			if cell.Excluded(i) {
+				target.SetExcluded(transform.MapNumber(i), true)
+			}
+			if cell.Mark(i) {
+				target.SetMark(transform.MapNumber(i), true)
+			}
+		}
+		if cell.Locked() {
+			target.Lock()
+		}
+	}
+
+	return result
+}
+
+func (self *gridImpl) Transform(transform *GridTransform) MutableGrid {
+	return transformGridImpl(self, transform)
+}
+
+func (self *mutableGridImpl) Transform(transform *GridTransform) MutableGrid {
+	result := transformGridImpl(self, transform)
+
+	//The transformed puzzle is logically the same puzzle, so there's no
+	//need to pay to calculate its difficulty again.
+	if resultImpl, ok := result.(*mutableGridImpl); ok {
+		resultImpl.cachedDifficulty = self.cachedDifficulty
+		resultImpl.cachedDifficultyDistribution = self.cachedDifficultyDistribution
+	}
+
+	return result
+}
+
+//mapCellRefs returns refs moved by transform.
+func (self *GridTransform) mapCellRefs(refs CellRefSlice) CellRefSlice {
+	if refs == nil {
+		return nil
+	}
+	result := make(CellRefSlice, len(refs))
+	for i, ref := range refs {
+		result[i] = self.MapCell(ref)
+	}
+	return result
+}
+
+//mapNumbers returns numbers relabeled by transform.
+func (self *GridTransform) mapNumbers(numbers IntSlice) IntSlice {
+	if numbers == nil {
+		return nil
+	}
+	result := make(IntSlice, len(numbers))
+	for i, number := range numbers {
+		result[i] = self.MapNumber(number)
+	}
+	return result
+}
+
+//Transform returns a copy of the step that does the same thing in a grid
+//that has been transformed by transform.
+func (self *SolveStep) Transform(transform *GridTransform) *SolveStep {
+	if self == nil {
+		return nil
+	}
+	result := &SolveStep{
+		Technique:    transform.mapTechnique(self.Technique),
+		TargetCells:  transform.mapCellRefs(self.TargetCells),
+		TargetNums:   transform.mapNumbers(self.TargetNums),
+		PointerCells: transform.mapCellRefs(self.PointerCells),
+		PointerNums:  transform.mapNumbers(self.PointerNums),
+		extra:        self.extra,
+	}
+	if result.Technique != nil {
+		result.normalize()
+	}
+	return result
+}
+
+//Transform returns a copy of the step that does the same thing in a grid
+//that has been transformed by transform.
+func (c *CompoundSolveStep) Transform(transform *GridTransform) *CompoundSolveStep {
+	if c == nil {
+		return nil
+	}
+	result := &CompoundSolveStep{
+		FillStep:    c.FillStep.Transform(transform),
+		explanation: c.explanation,
+	}
+	for _, step := range c.PrecursorSteps {
+		result.PrecursorSteps = append(result.PrecursorSteps, step.Transform(transform))
+	}
+	return result
+}
+
+//Transform returns directions to solve the grid after it has been
+//transformed by transform, so a disguised puzzle can reuse the solve of the
+//original.
+func (self SolveDirections) Transform(transform *GridTransform) SolveDirections {
+	result := SolveDirections{}
+	if self.gridSnapshot != nil {
+		result.gridSnapshot = self.gridSnapshot.Transform(transform).Copy()
+	}
+	for _, step := range self.CompoundSteps {
+		result.CompoundSteps = append(result.CompoundSteps, step.Transform(transform))
+	}
+	return result
+}
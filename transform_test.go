@@ -0,0 +1,211 @@
+package sudoku
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransformMatchesSymmetry(t *testing.T) {
+	grid := NewGrid()
+
+	rotate := RotateTransform(1)
+
+	for _, cell := range grid.Cells() {
+		partner := cell.SymmetricalPartner(SYMMETRY_ROTATIONAL_90)
+		expected := cell.Reference()
+		if partner != nil {
+			expected = partner.Reference()
+		}
+		if rotate.MapCell(cell.Reference()) != expected {
+			t.Error("Rotation didn't match SYMMETRY_ROTATIONAL_90 for", cell)
+			break
+		}
+	}
+
+	for _, symmetry := range []SymmetryType{SYMMETRY_HORIZONTAL, SYMMETRY_VERTICAL, SYMMETRY_DIAGONAL, SYMMETRY_ANTI_DIAGONAL} {
+		reflect, err := ReflectTransform(symmetry)
+		if err != nil {
+			t.Fatal("Got error for valid reflection:", err)
+		}
+		for _, cell := range grid.Cells() {
+			partner := cell.SymmetricalPartner(symmetry)
+			expected := cell.Reference()
+			if partner != nil {
+				expected = partner.Reference()
+			}
+			if reflect.MapCell(cell.Reference()) != expected {
+				t.Error("Reflection didn't match symmetry", symmetry, "for", cell)
+				break
+			}
+		}
+	}
+
+	if _, err := ReflectTransform(SYMMETRY_ROTATIONAL_90); err == nil {
+		t.Error("Didn't get an error for a symmetry that isn't a reflection")
+	}
+}
+
+func TestTransformComposition(t *testing.T) {
+	if *RotateTransform(4) != *IdentityTransform() {
+		t.Error("Four quarter turns wasn't the identity")
+	}
+
+	if *RotateTransform(1).Then(RotateTransform(-1)) != *IdentityTransform() {
+		t.Error("Rotating back and forth wasn't the identity")
+	}
+
+	if *RotateTransform(2) != *reversedTransform(true, true) {
+		t.Error("Half turn didn't reverse rows and columns")
+	}
+
+	for i := 0; i < 10; i++ {
+		transform := RandomTransform()
+		if *transform.Then(transform.Inverse()) != *IdentityTransform() {
+			t.Error("Transform followed by its inverse wasn't the identity")
+		}
+		if *transform.Inverse().Then(transform) != *IdentityTransform() {
+			t.Error("Inverse followed by transform wasn't the identity")
+		}
+	}
+
+	bands, _ := PermuteBandsTransform([]int{2, 0, 1})
+	rows, _ := PermuteRowsTransform(0, []int{1, 2, 0})
+
+	combined := bands.Then(rows)
+
+	//Row 0 is in band 0, which moves to band 2: row 6. Row 3 is in band 1,
+	//which moves to band 0, and is then moved to the third row of the band.
+	if combined.MapCell(CellRef{0, 4}) != (CellRef{6, 4}) {
+		t.Error("Wrong result for row in moved band:", combined.MapCell(CellRef{0, 4}))
+	}
+	if combined.MapCell(CellRef{3, 4}) != (CellRef{1, 4}) {
+		t.Error("Wrong result for row moved within band:", combined.MapCell(CellRef{3, 4}))
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	if _, err := PermuteBandsTransform([]int{0, 0, 1}); err == nil {
+		t.Error("No error for band permutation with duplicates")
+	}
+	if _, err := PermuteStacksTransform([]int{0, 1}); err == nil {
+		t.Error("No error for short stack permutation")
+	}
+	if _, err := PermuteRowsTransform(3, []int{0, 1, 2}); err == nil {
+		t.Error("No error for invalid band")
+	}
+	if _, err := PermuteColsTransform(0, []int{0, 1, 3}); err == nil {
+		t.Error("No error for out of range column permutation")
+	}
+	if _, err := RelabelTransform([]int{1, 2, 3, 4, 5, 6, 7, 8, 8}); err == nil {
+		t.Error("No error for labels with duplicates")
+	}
+	if _, err := RelabelTransform([]int{0, 1, 2, 3, 4, 5, 6, 7, 8}); err == nil {
+		t.Error("No error for labels including 0")
+	}
+}
+
+func TestGridTransform(t *testing.T) {
+	grid := LoadSDK(TEST_GRID).MutableCopy()
+	grid.LockFilledCells()
+
+	grid.MutableCell(0, 3).SetMark(5, true)
+	grid.MutableCell(0, 3).SetExcluded(7, true)
+
+	relabel, _ := RelabelTransform([]int{9, 8, 7, 6, 5, 4, 3, 2, 1})
+
+	transform := TransposeTransform().Then(relabel)
+
+	transformed := grid.Transform(transform)
+
+	cell := transformed.Cell(3, 0)
+
+	if !cell.Mark(5) || cell.Mark(4) {
+		t.Error("Mark wasn't transformed:", cell.Marks())
+	}
+
+	if !cell.Excluded(3) || cell.Excluded(7) {
+		t.Error("Exclude wasn't transformed")
+	}
+
+	if transformed.Cell(1, 0).Number() != 10-grid.Cell(0, 1).Number() {
+		t.Error("Number wasn't transformed")
+	}
+
+	if !transformed.Cell(0, 0).Locked() || transformed.Cell(0, 3).Locked() {
+		t.Error("Locks weren't transformed")
+	}
+
+	if !transformed.Equivalent(grid) {
+		t.Error("Transformed grid wasn't equivalent")
+	}
+
+	restored := transformed.Transform(transform.Inverse())
+
+	if restored.Diagram(true) != grid.Diagram(true) {
+		t.Error("Inverse didn't restore the grid. Got\n", restored.Diagram(true))
+	}
+
+	if !restored.Cell(0, 3).Mark(5) {
+		t.Error("Inverse didn't restore marks")
+	}
+
+	//The cached difficulty should carry over.
+	impl := grid.(*mutableGridImpl)
+	impl.cachedDifficulty = 0.42
+
+	if grid.Transform(RandomTransform()).Difficulty() != 0.42 {
+		t.Error("Transformed grid didn't reuse cached difficulty")
+	}
+}
+
+func TestSolveDirectionsTransform(t *testing.T) {
+	grid := LoadSDK(TEST_GRID)
+
+	directions := grid.HumanSolution(nil)
+
+	if directions == nil {
+		t.Fatal("Couldn't solve grid")
+	}
+
+	relabel, _ := RelabelTransform([]int{2, 3, 4, 5, 6, 7, 8, 9, 1})
+	bands, _ := PermuteBandsTransform([]int{1, 2, 0})
+
+	transform := TransposeTransform().Then(bands).Then(relabel)
+
+	transformed := directions.Transform(transform)
+
+	if len(transformed.CompoundSteps) != len(directions.CompoundSteps) {
+		t.Fatal("Transformed directions had wrong number of steps")
+	}
+
+	transformedGrid := grid.Transform(transform)
+
+	if transformed.Grid().DataString() != transformedGrid.DataString() {
+		t.Error("Transformed directions had wrong grid")
+	}
+
+	for i, step := range transformed.Steps() {
+		original := directions.Steps()[i].Technique.Name()
+		name := step.Technique.Name()
+		if strings.HasSuffix(original, " Row") && !strings.HasSuffix(name, " Col") {
+			t.Error("Row technique wasn't turned into col technique by transposing:", original, name)
+		}
+		if strings.HasSuffix(original, " Col") && !strings.HasSuffix(name, " Row") {
+			t.Error("Col technique wasn't turned into row technique by transposing:", original, name)
+		}
+	}
+
+	for _, step := range transformed.CompoundSteps {
+		step.Apply(transformedGrid)
+	}
+
+	if !transformedGrid.Solved() {
+		t.Error("Transformed directions didn't solve the transformed grid")
+	}
+
+	solution := grid.Solutions()[0].Transform(transform)
+
+	if transformedGrid.DataString() != solution.DataString() {
+		t.Error("Transformed directions didn't give the transformed solution")
+	}
+}
@@ -1,6 +1,7 @@
 package sudoku
 
 import (
+	"errors"
 	"math"
 	"sort"
 )
@@ -15,6 +16,16 @@ type probabilityTweak float64
 //proposedStep is applied.
 type probabilityTwiddler func(proposedStep *SolveStep, inProgressCompoundStep []*SolveStep, pastSteps []*CompoundSolveStep, previousGrid Grid) probabilityTweak
 
+//ProbabilityTwiddler is the public version of the functions that HumanSolve
+//uses to model the biases humans have about which step to pick next. It
+//should return a value between 0.0 (no change; good) and 1.0 (maximal
+//change; bad), which will then be multiplied by the twiddler's weight.
+//previousGrid is the grid state BEFORE the proposedStep is applied;
+//inProgressCompoundStep are the steps already picked in the current
+//CompoundSolveStep, and pastSteps are the CompoundSolveSteps that came
+//before. Register new ones with RegisterTwiddler.
+type ProbabilityTwiddler func(proposedStep *SolveStep, inProgressCompoundStep []*SolveStep, pastSteps []*CompoundSolveStep, previousGrid Grid) float64
+
 type probabilityTwiddlerItem struct {
 	f      probabilityTwiddler
 	name   string
@@ -60,6 +71,82 @@ func init() {
 	}
 }
 
+//RegisterTwiddler adds a new twiddler that HumanSolve will use to decide
+//which step to pick next. weight is the default weight for the twiddler, and
+//can be overridden by HumanSolveOptions.TwiddlerWeights. Returns an error if
+//a twiddler with that name already exists. Twiddlers should be registered
+//before any solving happens (for example, in an init function).
+func RegisterTwiddler(name string, twiddler ProbabilityTwiddler, weight float64) error {
+	if name == "" {
+		return errors.New("Twiddler has no name")
+	}
+	if twiddler == nil {
+		return errors.New("No twiddler provided")
+	}
+	for _, item := range twiddlers {
+		if item.name == name {
+			return errors.New("A twiddler named " + name + " already exists")
+		}
+	}
+
+	f := func(proposedStep *SolveStep, inProgressCompoundStep []*SolveStep, pastSteps []*CompoundSolveStep, previousGrid Grid) probabilityTweak {
+		return probabilityTweak(twiddler(proposedStep, inProgressCompoundStep, pastSteps, previousGrid))
+	}
+
+	//Don't modify twiddlers in place, in case a searcher is using it.
+	newTwiddlers := make([]probabilityTwiddlerItem, 0, len(twiddlers)+1)
+	newTwiddlers = append(newTwiddlers, twiddlers...)
+	twiddlers = append(newTwiddlers, probabilityTwiddlerItem{
+		f:      f,
+		name:   name,
+		weight: probabilityTweak(weight),
+	})
+
+	return nil
+}
+
+//TwiddlerNames returns the names of all of the registered twiddlers, in the
+//order they are applied. These are the names that
+//HumanSolveOptions.TwiddlerWeights uses.
+func TwiddlerNames() []string {
+	var result []string
+	for _, item := range twiddlers {
+		result = append(result, item.name)
+	}
+	return result
+}
+
+//TwiddlerWeight returns the default weight of the twiddler with the given
+//name, and whether such a twiddler exists.
+func TwiddlerWeight(name string) (float64, bool) {
+	for _, item := range twiddlers {
+		if item.name == name {
+			return float64(item.weight), true
+		}
+	}
+	return 0.0, false
+}
+
+//effectiveTwiddlers returns the list of twiddlers to use with the given
+//options, with any overridden weights applied and disabled twiddlers removed.
+func effectiveTwiddlers(options *HumanSolveOptions) []probabilityTwiddlerItem {
+	if options == nil || len(options.TwiddlerWeights) == 0 {
+		return twiddlers
+	}
+	var result []probabilityTwiddlerItem
+	for _, item := range twiddlers {
+		if weight, ok := options.TwiddlerWeights[item.name]; ok {
+			if weight == 0.0 {
+				//Disabled
+				continue
+			}
+			item.weight = probabilityTweak(weight)
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
 func (p *probabilityTwiddlerItem) Twiddle(proposedStep *SolveStep, inProgressCompoundStep []*SolveStep, pastSteps []*CompoundSolveStep, previousGrid Grid) probabilityTweak {
 
 	result := p.f(proposedStep, inProgressCompoundStep, pastSteps, previousGrid)
@@ -2,6 +2,7 @@ package sudoku
 
 import (
 	"math"
+	"reflect"
 	"testing"
 )
 
@@ -390,3 +391,118 @@ func TestTwiddlePreferFilledGroups(t *testing.T) {
 
 	testHelper(0.0782608695652174, "Full block, row, col")
 }
+
+func TestRegisterTwiddler(t *testing.T) {
+
+	oldTwiddlers := twiddlers
+	defer func() {
+		twiddlers = oldTwiddlers
+	}()
+
+	numTwiddlers := len(TwiddlerNames())
+
+	calledTwiddler := false
+
+	twiddler := func(proposedStep *SolveStep, inProgressCompoundStep []*SolveStep, pastSteps []*CompoundSolveStep, previousGrid Grid) float64 {
+		calledTwiddler = true
+		return 0.5
+	}
+
+	if err := RegisterTwiddler("Test Twiddler", twiddler, 2.0); err != nil {
+		t.Fatal("Couldn't register twiddler:", err)
+	}
+
+	names := TwiddlerNames()
+
+	if len(names) != numTwiddlers+1 || names[len(names)-1] != "Test Twiddler" {
+		t.Error("Registered twiddler not in TwiddlerNames:", names)
+	}
+
+	if weight, ok := TwiddlerWeight("Test Twiddler"); !ok || weight != 2.0 {
+		t.Error("Registered twiddler had wrong weight:", weight, ok)
+	}
+
+	if err := RegisterTwiddler("Test Twiddler", twiddler, 2.0); err == nil {
+		t.Error("Didn't get an error registering a twiddler with the same name twice")
+	}
+
+	if err := RegisterTwiddler("Chained Steps", twiddler, 2.0); err == nil {
+		t.Error("Didn't get an error registering a twiddler with a built-in's name")
+	}
+
+	grid := LoadSDK(TEST_GRID)
+
+	grid.Hint(nil, nil)
+
+	if !calledTwiddler {
+		t.Error("HumanSolve didn't use the registered twiddler")
+	}
+}
+
+func TestEffectiveTwiddlers(t *testing.T) {
+
+	if !reflect.DeepEqual(TwiddlerNames(), []string{"Human Likelihood", "Chained Steps", "Common Numbers", "Pointing Target Overlap", "Prefer Filled Groups"}) {
+		t.Error("Unexpected twiddler names:", TwiddlerNames())
+	}
+
+	options := DefaultHumanSolveOptions()
+
+	if len(effectiveTwiddlers(options)) != len(twiddlers) {
+		t.Error("Default options didn't use all twiddlers")
+	}
+
+	options.TwiddlerWeights = map[string]float64{
+		"Chained Steps":  0.0,
+		"Common Numbers": 8.0,
+	}
+
+	effective := effectiveTwiddlers(options)
+
+	var names []string
+
+	for _, item := range effective {
+		names = append(names, item.name)
+		if item.name == "Common Numbers" && item.weight != 8.0 {
+			t.Error("Common Numbers weight wasn't overridden:", item.weight)
+		}
+		if item.name == "Prefer Filled Groups" && item.weight != 10.0 {
+			t.Error("Prefer Filled Groups weight was changed:", item.weight)
+		}
+	}
+
+	if !reflect.DeepEqual(names, []string{"Human Likelihood", "Common Numbers", "Pointing Target Overlap", "Prefer Filled Groups"}) {
+		t.Error("Wrong effective twiddlers:", names)
+	}
+
+	if weight, _ := TwiddlerWeight("Common Numbers"); weight != 4.0 {
+		t.Error("Overriding weights in options changed the default weight")
+	}
+
+	searcher := newHumanSolveSearcher(LoadSDK(TEST_GRID), nil, options)
+
+	if len(searcher.twiddlers) != len(effective) {
+		t.Error("Searcher didn't use the options' twiddlers")
+	}
+
+	if err := options.validate(); err != nil {
+		t.Error("Options with known twiddler names didn't validate:", err)
+	}
+
+	//A typo in a twiddler's name shouldn't quietly fall back on the default
+	//weights.
+	options.TwiddlerWeights["Not A Twiddler"] = 3.0
+
+	if err := options.validate(); err == nil {
+		t.Error("Options with an unknown twiddler name validated")
+	}
+
+	grid := LoadSDK(TEST_GRID)
+
+	if grid.Hint(options, nil) != nil {
+		t.Error("Got a hint with an unknown twiddler name")
+	}
+
+	if steps, _ := grid.HumanSolvePossibleSteps(options, nil); steps != nil {
+		t.Error("Got possible steps with an unknown twiddler name")
+	}
+}